@@ -0,0 +1,385 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/i18n"
+	"sbermortgagecalculator/internal/models"
+	"sbermortgagecalculator/internal/utils"
+	"sbermortgagecalculator/internal/validation"
+)
+
+// Output formats of the command-line mode.
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// Errors of the command-line mode.
+var (
+	ErrUnknownCommand = errors.New("unknown command, use calc, schedule or batch")
+	ErrUnknownFormat  = errors.New("unknown output format")
+	ErrNoInput        = errors.New("set the input file with --input")
+	ErrCSVHeader      = errors.New("CSV input should start with the header object_cost,initial_payment,months,program[,payment_type]")
+)
+
+// csvColumns are the columns of the CSV input of the batch subcommand.
+var csvColumns = []string{"object_cost", "initial_payment", "months", "program", "payment_type"}
+
+// commands run the calculations offline, without starting the server.
+var commands = map[string]func(args []string, stdout io.Writer) error{
+	"calc":     runCalc,
+	"schedule": runSchedule,
+	"batch":    runBatch,
+}
+
+// runCommand executes the command-line subcommand with its arguments.
+func runCommand(name string, args []string, stdout io.Writer) error {
+	command, ok := commands[name]
+	if !ok {
+		return ErrUnknownCommand
+	}
+	return command(args, stdout)
+}
+
+// loanFlags binds the loan request and output flags shared by the calc and schedule subcommands.
+type loanFlags struct {
+	request models.LoanRequest
+	program string
+	format  string
+	config  string
+}
+
+func newLoanFlagSet(name string, defaultFormat string) (*flag.FlagSet, *loanFlags) {
+	flags := &loanFlags{}
+	set := flag.NewFlagSet(name, flag.ContinueOnError)
+	set.IntVar(&flags.request.ObjectCost, "cost", 0, "The cost of the object")
+	set.IntVar(&flags.request.InitialPayment, "down", 0, "The initial payment")
+	set.IntVar(&flags.request.Months, "months", 0, "The loan term in months")
+	set.StringVar(&flags.program, "program", "", "The loan program identifier")
+	set.StringVar(&flags.request.PaymentType, "payment-type", models.PaymentTypeAnnuity, "The payment scheme: annuity or differentiated")
+	set.IntVar(&flags.request.PaymentDay, "payment-day", 0, "The day of the month the payments are due, today's day if 0")
+	set.StringVar(&flags.request.IssueDate, "issue-date", "", "The loan issue date in the YYYY-MM-DD format, today if empty")
+	set.StringVar(&flags.format, "format", defaultFormat, "The output format")
+	set.StringVar(&flags.config, "config", "", "The path to the configuration file with programs and rounding, built-in programs if empty")
+	return set, flags
+}
+
+// parse parses the arguments, applies the configuration file and validates the request as the server does.
+func (f *loanFlags) parse(set *flag.FlagSet, args []string) error {
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	f.request.Program = models.Program{ID: f.program}
+	if err := applyConfig(f.config); err != nil {
+		return err
+	}
+	if fieldErrors := validateLoan(f.request); len(fieldErrors) > 0 {
+		return fmt.Errorf("%w: %s", calculator.ErrInvalidRequest, fieldErrorsText(fieldErrors))
+	}
+	return nil
+}
+
+// validateLoan returns the errors of the loan request fields with the messages in the default language.
+func validateLoan(request models.LoanRequest) []models.FieldError {
+	return validation.LoanRequest(request, i18n.DefaultLanguage)
+}
+
+// applyConfig configures the calculator as the server does: the programs, rounding, affordability, batch and
+// cache limits, the payment calendar and the key rates of the configuration file.
+func applyConfig(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	config, err := utils.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	if err = configureCalculator(config); err != nil {
+		return err
+	}
+	if err = configureCalendar(path, config.Calendar); err != nil {
+		return err
+	}
+	return configureKeyRates(path, config.KeyRate)
+}
+
+// runCalc prints the aggregates of a single loan.
+func runCalc(args []string, stdout io.Writer) error {
+	set, flags := newLoanFlagSet("calc", formatText)
+	if err := flags.parse(set, args); err != nil {
+		return err
+	}
+
+	result, err := calculator.CalculateLoan(flags.request)
+	if err != nil {
+		return err
+	}
+
+	switch flags.format {
+	case formatJSON:
+		return writeJSON(stdout, result)
+	case formatText:
+		table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		aggregates := result.Aggregates
+		for _, row := range [][2]string{
+			{"Rate, %", aggregates.Rate.String()},
+			{"Loan sum", aggregates.LoanSum.StringFixed(2)},
+			{"Monthly payment", aggregates.MonthlyPayment.StringFixed(2)},
+			{"First payment", aggregates.FirstPayment.StringFixed(2)},
+			{"Last payment", aggregates.LastPayment.StringFixed(2)},
+			{"Max payment", aggregates.MaxPayment.StringFixed(2)},
+			{"Overpayment", aggregates.Overpayment.StringFixed(2)},
+			{"Last payment date", aggregates.LastPaymentDate},
+		} {
+			fmt.Fprintf(table, "%s:\t%s\n", row[0], row[1])
+		}
+		for _, period := range aggregates.Periods {
+			fmt.Fprintf(table, "Payments %d-%d:\t%s at %s%%\n",
+				period.FromPayment, period.ToPayment, period.MonthlyPayment.StringFixed(2), period.Rate)
+		}
+		return table.Flush()
+	default:
+		return fmt.Errorf("%w %q, use text or json", ErrUnknownFormat, flags.format)
+	}
+}
+
+// runSchedule prints the payment schedule of a single loan.
+func runSchedule(args []string, stdout io.Writer) error {
+	set, flags := newLoanFlagSet("schedule", formatText)
+	if err := flags.parse(set, args); err != nil {
+		return err
+	}
+
+	schedule, err := calculator.CalculatePaymentSchedule(flags.request)
+	if err != nil {
+		return err
+	}
+
+	switch flags.format {
+	case formatJSON:
+		return writeJSON(stdout, schedule)
+	case formatCSV:
+		return writeScheduleCSV(stdout, schedule)
+	case formatText:
+		table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(table, "No\tDate\tPayment\tInterest\tPrincipal\tPrepayment\tBalance\t")
+		for _, row := range schedule {
+			fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t\n", row.Number, row.Date, row.Payment.StringFixed(2),
+				row.Interest.StringFixed(2), row.Principal.StringFixed(2), row.Prepayment.StringFixed(2), row.Balance.StringFixed(2))
+		}
+		return table.Flush()
+	default:
+		return fmt.Errorf("%w %q, use text, csv or json", ErrUnknownFormat, flags.format)
+	}
+}
+
+// writeScheduleCSV writes the payment schedule as CSV with a header.
+func writeScheduleCSV(stdout io.Writer, schedule []models.SchedulePayment) error {
+	writer := csv.NewWriter(stdout)
+	if err := writer.Write([]string{"number", "date", "payment", "interest", "principal", "prepayment", "balance"}); err != nil {
+		return err
+	}
+	for _, row := range schedule {
+		record := []string{strconv.Itoa(row.Number), row.Date, row.Payment.StringFixed(2), row.Interest.StringFixed(2),
+			row.Principal.StringFixed(2), row.Prepayment.StringFixed(2), row.Balance.StringFixed(2)}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// runBatch calculates the requests of a CSV or JSON file and prints a line per request.
+func runBatch(args []string, stdout io.Writer) error {
+	set := flag.NewFlagSet("batch", flag.ContinueOnError)
+	input := set.String("input", "", "The CSV or JSON file with loan requests")
+	format := set.String("format", formatJSON, "The output format: json (a line per request) or csv")
+	config := set.String("config", "", "The path to the configuration file with programs and rounding, built-in programs if empty")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *format != formatJSON && *format != formatCSV {
+		return fmt.Errorf("%w %q, use json or csv", ErrUnknownFormat, *format)
+	}
+	if *input == "" {
+		return ErrNoInput
+	}
+	if err := applyConfig(*config); err != nil {
+		return err
+	}
+
+	requests, err := readBatchFile(*input)
+	if err != nil {
+		return err
+	}
+
+	writeItem, flush := batchWriter(stdout, *format)
+	maxSize := calculator.BatchLimits().MaxSize
+	for start := 0; start < len(requests); start += maxSize {
+		items, batchErr := calculator.CalculateBatch(requests[start:min(start+maxSize, len(requests))], validateLoan)
+		if batchErr != nil {
+			return batchErr
+		}
+		for _, item := range items {
+			item.Index += start
+			if err = writeItem(item, requests[item.Index]); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// batchWriter returns the functions writing the batch items in the output format.
+func batchWriter(stdout io.Writer, format string) (func(models.BatchItem, models.LoanRequest) error, func() error) {
+	if format == formatJSON {
+		encoder := json.NewEncoder(stdout)
+		return func(item models.BatchItem, _ models.LoanRequest) error { return encoder.Encode(item) }, func() error { return nil }
+	}
+
+	writer := csv.NewWriter(stdout)
+	header := []string{"index", "object_cost", "initial_payment", "months", "program", "rate", "monthly_payment", "overpayment", "error"}
+	headerWritten := false
+	writeItem := func(item models.BatchItem, request models.LoanRequest) error {
+		if !headerWritten {
+			headerWritten = true
+			if err := writer.Write(header); err != nil {
+				return err
+			}
+		}
+		record := []string{strconv.Itoa(item.Index), strconv.Itoa(request.ObjectCost), strconv.Itoa(request.InitialPayment),
+			strconv.Itoa(request.Months), request.Program.ID, "", "", "", itemError(item)}
+		if item.Result != nil {
+			record[5] = item.Result.Aggregates.Rate.String()
+			record[6] = item.Result.Aggregates.MonthlyPayment.StringFixed(2)
+			record[7] = item.Result.Aggregates.Overpayment.StringFixed(2)
+		}
+		return writer.Write(record)
+	}
+	flush := func() error {
+		writer.Flush()
+		return writer.Error()
+	}
+	return writeItem, flush
+}
+
+// itemError returns the error of the batch item followed by the errors of the request fields.
+func itemError(item models.BatchItem) string {
+	if len(item.Errors) == 0 {
+		return item.Error
+	}
+	return item.Error + ": " + fieldErrorsText(item.Errors)
+}
+
+// fieldErrorsText joins the errors of the request fields with their names.
+func fieldErrorsText(fieldErrors []models.FieldError) string {
+	fields := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		fields = append(fields, fieldError.Field+" "+fieldError.Message)
+	}
+	return strings.Join(fields, "; ")
+}
+
+// readBatchFile reads the loan requests of a JSON array or a CSV file with a header, selected by the file extension.
+func readBatchFile(path string) ([]models.LoanRequest, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input: %w", err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readBatchCSV(file)
+	}
+
+	var requests []models.LoanRequest
+	if err = json.NewDecoder(file).Decode(&requests); err != nil {
+		return nil, fmt.Errorf("failed to decode input: %w", err)
+	}
+	return requests, nil
+}
+
+// readBatchCSV reads the loan requests of a CSV file with the header object_cost,initial_payment,months,program[,payment_type].
+func readBatchCSV(input io.Reader) ([]models.LoanRequest, error) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV input: %w", err)
+	}
+	if len(records) == 0 || !validCSVHeader(records[0]) {
+		return nil, ErrCSVHeader
+	}
+
+	requests := make([]models.LoanRequest, 0, len(records)-1)
+	for line, record := range records[1:] {
+		request, parseErr := parseCSVRequest(record)
+		if parseErr != nil {
+			return nil, fmt.Errorf("CSV line %d: %w", line+2, parseErr)
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// validCSVHeader reports whether the header names the columns of the CSV input in order, the payment type is optional.
+func validCSVHeader(header []string) bool {
+	if len(header) < len(csvColumns)-1 || len(header) > len(csvColumns) {
+		return false
+	}
+	for i, column := range header {
+		if strings.TrimSpace(column) != csvColumns[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCSVRequest converts a CSV record to the loan request.
+func parseCSVRequest(record []string) (models.LoanRequest, error) {
+	if len(record) < 4 {
+		return models.LoanRequest{}, ErrCSVHeader
+	}
+
+	var params [3]int
+	for i := range params {
+		value, err := strconv.Atoi(record[i])
+		if err != nil {
+			return models.LoanRequest{}, err
+		}
+		params[i] = value
+	}
+
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{ObjectCost: params[0], InitialPayment: params[1], Months: params[2]},
+		Program:    models.Program{ID: record[3]},
+	}
+	if len(record) > 4 {
+		request.PaymentType = record[4]
+	}
+	return request, nil
+}
+
+// writeJSON writes the value as indented JSON.
+func writeJSON(stdout io.Writer, value any) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
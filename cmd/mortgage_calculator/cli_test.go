@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sbermortgagecalculator/internal/calculator"
+)
+
+func TestRunCommand(t *testing.T) {
+	loan := []string{"--cost", "5000000", "--down", "1000000", "--months", "240", "--program", "salary"}
+
+	tests := []struct {
+		name     string
+		command  string
+		args     []string
+		expected string
+	}{
+		{name: "Calc", command: "calc", args: loan, expected: "Monthly payment:    33457.60\n"},
+		{name: "Calc JSON", command: "calc", args: append([]string{"--format", "json"}, loan...), expected: `"monthly_payment": "33457.60"`},
+		{name: "Schedule CSV", command: "schedule", args: append([]string{"--format", "csv"}, loan...), expected: "number,date,payment,interest,principal,prepayment,balance\n1,"},
+		{name: "Schedule", command: "schedule", args: loan, expected: "33457.60"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			assert.NoError(t, runCommand(tc.command, tc.args, &stdout))
+			assert.Contains(t, stdout.String(), tc.expected)
+		})
+	}
+}
+
+func TestRunCommandErrors(t *testing.T) {
+	var stdout bytes.Buffer
+	assert.ErrorIs(t, runCommand("serve", nil, &stdout), ErrUnknownCommand)
+	assert.ErrorIs(t, runCommand("calc", []string{"--format", "xml", "--cost", "5000000", "--down", "1000000", "--months", "240", "--program", "salary"}, &stdout), ErrUnknownFormat)
+	assert.ErrorIs(t, runCommand("batch", nil, &stdout), ErrNoInput)
+
+	invalid := []string{"--cost", "5000000", "--down", "-1000000", "--months", "200000", "--program", "salary"}
+	for _, command := range []string{"calc", "schedule"} {
+		err := runCommand(command, invalid, &stdout)
+		assert.ErrorIs(t, err, calculator.ErrInvalidRequest, command)
+		assert.EqualError(t, err, "the request has invalid fields: initial_payment must not be negative; months must not exceed 600", command)
+	}
+	assert.Error(t, runCommand("calc", []string{"--program", "salary"}, &stdout))
+}
+
+func TestRunBatch(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "requests.csv")
+	csvInput := "object_cost,initial_payment,months,program,payment_type\n" +
+		"5000000,1000000,240,salary,annuity\n" +
+		"5000000,1000000,240,,\n" +
+		"5000000,-1000000,200000,salary,\n"
+	assert.NoError(t, os.WriteFile(csvPath, []byte(csvInput), 0o600))
+
+	var stdout bytes.Buffer
+	assert.NoError(t, runCommand("batch", []string{"--input", csvPath, "--format", "csv"}, &stdout))
+	assert.Equal(t, []string{
+		"index,object_cost,initial_payment,months,program,rate,monthly_payment,overpayment,error",
+		"0,5000000,1000000,240,salary,8,33457.60,4029825.57,",
+		"1,5000000,1000000,240,,,,,choose program",
+		"2,5000000,-1000000,200000,salary,,,,the request has invalid fields: initial_payment must not be negative; months must not exceed 600",
+	}, strings.Split(strings.TrimSpace(stdout.String()), "\n"))
+
+	jsonPath := filepath.Join(dir, "requests.json")
+	jsonInput := `[{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"military":true}}]`
+	assert.NoError(t, os.WriteFile(jsonPath, []byte(jsonInput), 0o600))
+
+	stdout.Reset()
+	assert.NoError(t, runCommand("batch", []string{"--input", jsonPath}, &stdout))
+	assert.Contains(t, stdout.String(), `"monthly_payment":"35989.04"`)
+
+	badPath := filepath.Join(dir, "bad.csv")
+	for _, header := range []string{"cost,down", "object_cost,down,months,program", "object_cost,initial_payment,months,program,payment_type,rate"} {
+		assert.NoError(t, os.WriteFile(badPath, []byte(header+"\n5000000,1000000,240,salary\n"), 0o600))
+		assert.ErrorIs(t, runCommand("batch", []string{"--input", badPath}, &stdout), ErrCSVHeader, header)
+	}
+}
+
+func TestRunBatchConfig(t *testing.T) {
+	limits := calculator.AffordabilityLimits()
+	t.Cleanup(func() {
+		assert.NoError(t, calculator.SetAffordabilityLimits(limits))
+	})
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	config := "affordability:\n  approve_pdn: 10\n  max_pdn: 20\n  dependant_allowance: 15000\n"
+	assert.NoError(t, os.WriteFile(configPath, []byte(config), 0o600))
+
+	jsonPath := filepath.Join(dir, "requests.json")
+	jsonInput := `[{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true},"borrower":{"income":100000}}]`
+	assert.NoError(t, os.WriteFile(jsonPath, []byte(jsonInput), 0o600))
+
+	var stdout bytes.Buffer
+	assert.NoError(t, runCommand("batch", []string{"--input", jsonPath, "--config", configPath}, &stdout))
+	assert.Contains(t, stdout.String(), `"code":"debt_load_too_high"`)
+}
@@ -2,21 +2,57 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/calendar"
+	"sbermortgagecalculator/internal/i18n"
+	"sbermortgagecalculator/internal/keyrate"
+	"sbermortgagecalculator/internal/logging"
 	"sbermortgagecalculator/internal/middleware"
 	"sbermortgagecalculator/internal/routes"
+	"sbermortgagecalculator/internal/routes/paths"
+	"sbermortgagecalculator/internal/storage"
 	"sbermortgagecalculator/internal/utils"
 )
 
 func main() {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		if err := runCommand(os.Args[1], os.Args[2:], os.Stdout); err != nil {
+			if !errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
+	serve()
+}
+
+// messagesFile is the name of the optional message catalogue next to the configuration file.
+const messagesFile = "messages.yml"
+
+// defaultDrainTimeout is the time to wait for the requests in flight on shutdown when not configured.
+const defaultDrainTimeout = 30 * time.Second
+
+// serve starts the HTTP server configured by the file from the -config flag and shuts it down gracefully on SIGINT or SIGTERM.
+func serve() {
 	configPath := flag.String("config", "config.yml", "The path to the configuration file")
 	flag.Parse()
 	if configPath == nil {
@@ -26,29 +62,205 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error load config server: %v", err)
 	}
+	logger, err := logging.New(os.Stderr, config.Logging.Format, config.Logging.Level)
+	if err != nil {
+		log.Fatalf("Error set logger: %v", err)
+	}
+	slog.SetDefault(logger)
 
-	r := mux.NewRouter()
+	if err = configureCalculator(config); err != nil {
+		log.Fatal(err)
+	}
+	if err = configureMessages(*configPath); err != nil {
+		log.Fatal(err)
+	}
+	if err = configureCalendar(*configPath, config.Calendar); err != nil {
+		log.Fatal(err)
+	}
+	if err = configureKeyRates(*configPath, config.KeyRate); err != nil {
+		log.Fatal(err)
+	}
 
-	r.Use(middleware.LoggingMiddleware)
+	if err = run(config); err != nil {
+		log.Fatal(err)
+	}
+}
 
-	routes.SetupRoutes(r)
+// run serves the requests until the shutdown signal, or until the server or the opening of the store fails.
+func run(config *utils.Config) error {
+	// The persistent store replays its file in the background, the readiness probe fails until it is loaded.
+	store, loaded := storage.OpenAsync(config.Storage.Type, config.Storage.Path, config.Cache.Loans)
+	paths.SetLoanStore(store)
 
-	corsMiddleware := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	address := fmt.Sprintf(":%d", config.Port)
-	log.Printf("The server is running on the port %s\n", address)
 	srv := &http.Server{
 		Addr:         address,
-		Handler:      corsMiddleware(r),
+		Handler:      newHandler(),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  20 * time.Second,
 	}
-	if err := srv.ListenAndServe(); err != nil {
-		log.Fatalf("Server startup error: %v", err)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.ListenAndServe()
+	}()
+	slog.Info("The server is running", "address", address)
+
+	err := wait(ctx, loaded, serverErr)
+	if err == nil {
+		// A second signal terminates the process without waiting.
+		stop()
+		shutdown(srv, config.Shutdown)
+		slog.Info("The server is stopped")
+	}
+	if closeErr := store.Close(); closeErr != nil {
+		slog.Error("Error closing storage", "error", closeErr)
+	}
+	return err
+}
+
+// configureCalculator applies the programs and limits of the configuration to the calculator.
+func configureCalculator(config *utils.Config) error {
+	if len(config.Programs) > 0 {
+		if err := calculator.SetPrograms(config.Programs); err != nil {
+			return fmt.Errorf("error load loan programs: %w", err)
+		}
+	}
+
+	calculator.SetCacheLimits(config.Cache.Aggregates)
+	if config.Rounding != "" {
+		if err := calculator.SetRoundingMode(config.Rounding); err != nil {
+			return fmt.Errorf("error set rounding mode: %w", err)
+		}
+	}
+	if config.Affordability != nil {
+		if err := calculator.SetAffordabilityLimits(*config.Affordability); err != nil {
+			return fmt.Errorf("error set affordability limits: %w", err)
+		}
+	}
+	if err := calculator.SetBatchLimits(config.Batch); err != nil {
+		return fmt.Errorf("error set batch limits: %w", err)
+	}
+	return nil
+}
+
+// configureMessages adds the messages of the catalogue next to the configuration file, if there is one,
+// to the built-in English and Russian messages.
+func configureMessages(configPath string) error {
+	catalog, err := i18n.LoadFile(filepath.Join(filepath.Dir(configPath), messagesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = i18n.SetCatalog(catalog); err != nil {
+		return fmt.Errorf("error set messages: %w", err)
+	}
+	return nil
+}
+
+// configureCalendar loads the production calendar of the payment dates, with the path relative to the configuration file.
+func configureCalendar(configPath string, config utils.CalendarConfig) error {
+	if config.Path == "" {
+		calculator.SetCalendar(nil)
+		return nil
+	}
+
+	path := config.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(configPath), path)
+	}
+	production, err := calendar.Load(path)
+	if err != nil {
+		return fmt.Errorf("error load production calendar: %w", err)
+	}
+	calculator.SetCalendar(production)
+	return nil
+}
+
+// configureKeyRates loads the key rate schedule of the floating rates, with the path relative to the configuration file.
+func configureKeyRates(configPath string, config utils.KeyRateConfig) error {
+	if config.Path == "" {
+		calculator.SetKeyRates(nil)
+		return nil
+	}
+
+	path := config.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(configPath), path)
+	}
+	schedule, err := keyrate.Load(path)
+	if err != nil {
+		return fmt.Errorf("error load key rate schedule: %w", err)
+	}
+	calculator.SetKeyRates(schedule)
+	return nil
+}
+
+// newHandler creates the router with the middleware and the CORS policy.
+func newHandler() http.Handler {
+	r := mux.NewRouter()
+
+	r.Use(middleware.LoggingMiddleware)
+
+	// The router middleware runs for the matched routes only, so the unmatched requests are wrapped separately
+	// to be logged and counted.
+	r.NotFoundHandler = middleware.LoggingMiddleware(http.NotFoundHandler())
+	r.MethodNotAllowedHandler = middleware.LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+
+	routes.SetupRoutes(r)
+
+	corsMiddleware := handlers.CORS(
+		handlers.AllowedOrigins([]string{"*"}),
+		handlers.AllowedMethods([]string{"GET", "POST", "DELETE", "OPTIONS"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "Accept-Language", middleware.RequestIDHeader}),
+		handlers.ExposedHeaders([]string{middleware.RequestIDHeader}),
+	)
+	return corsMiddleware(r)
+}
+
+// wait blocks until the shutdown signal. It returns the error if the server or the opening of the store fails first.
+func wait(ctx context.Context, loaded <-chan error, serverErr <-chan error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-serverErr:
+			return fmt.Errorf("server startup error: %w", err)
+		case err := <-loaded:
+			if err != nil {
+				return fmt.Errorf("error open storage: %w", err)
+			}
+			slog.Info("The storage is loaded")
+			loaded = nil
+		}
+	}
+}
+
+// shutdown fails the readiness probe for the drain delay, then stops accepting requests
+// and waits up to the drain timeout for the requests in flight.
+func shutdown(srv *http.Server, config utils.ShutdownConfig) {
+	timeout := config.DrainTimeout
+	if timeout <= 0 {
+		timeout = defaultDrainTimeout
+	}
+
+	paths.SetDraining(true)
+	slog.Info("Shutting down", "drain_delay", config.DrainDelay, "drain_timeout", timeout)
+	time.Sleep(config.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("The requests in flight did not complete in the drain timeout", "error", err)
+		if closeErr := srv.Close(); closeErr != nil {
+			slog.Error("Error closing server", "error", closeErr)
+		}
 	}
 }
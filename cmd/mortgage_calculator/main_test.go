@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/i18n"
+	"sbermortgagecalculator/internal/logging"
+	"sbermortgagecalculator/internal/routes/paths"
+	"sbermortgagecalculator/internal/utils"
+)
+
+func TestShutdown(t *testing.T) {
+	defer paths.SetDraining(false)
+
+	started := make(chan struct{})
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			_, _ = w.Write([]byte("done"))
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+
+	body := make(chan string, 1)
+	go func() {
+		response, getErr := http.Get("http://" + listener.Addr().String())
+		if getErr != nil {
+			body <- getErr.Error()
+			return
+		}
+		defer response.Body.Close()
+		data, _ := io.ReadAll(response.Body)
+		body <- string(data)
+	}()
+
+	<-started
+	shutdown(srv, utils.ShutdownConfig{DrainTimeout: time.Second})
+	assert.Equal(t, "done", <-body, "the request in flight should complete")
+
+	rec := httptest.NewRecorder()
+	paths.GetReadiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	_, err = http.Get("http://" + listener.Addr().String())
+	assert.Error(t, err, "the server should not accept requests after the shutdown")
+}
+
+func TestWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	loaded := make(chan error, 1)
+	loaded <- nil
+	cancel()
+	assert.NoError(t, wait(ctx, loaded, make(chan error)))
+
+	failed := make(chan error, 1)
+	failed <- errors.New("corrupted")
+	assert.ErrorContains(t, wait(context.Background(), failed, make(chan error)), "error open storage: corrupted")
+
+	serverErr := make(chan error, 1)
+	serverErr <- http.ErrServerClosed
+	assert.ErrorIs(t, wait(context.Background(), make(chan error), serverErr), http.ErrServerClosed)
+}
+
+func TestConfigureMessages(t *testing.T) {
+	defer func() {
+		require.NoError(t, i18n.SetCatalog(nil))
+	}()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	require.NoError(t, configureMessages(configPath), "the catalogue file is optional")
+
+	content := "ru:\n  program.family: Семейная ипотека\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, messagesFile), []byte(content), 0o600))
+	require.NoError(t, configureMessages(configPath))
+	assert.Equal(t, "Семейная ипотека", i18n.Message(i18n.Russian, i18n.PrefixProgram+"family"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, messagesFile), []byte("ru: [broken"), 0o600))
+	assert.Error(t, configureMessages(configPath))
+}
+
+func TestConfigureCalendar(t *testing.T) {
+	defer calculator.SetCalendar(nil)
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "calendar.yml"), []byte("holidays:\n  - 2026-01-09\n"), 0o600))
+
+	require.NoError(t, configureCalendar(configPath, utils.CalendarConfig{Path: "calendar.yml"}))
+	holiday := time.Date(2026, time.January, 9, 0, 0, 0, 0, time.UTC)
+	assert.False(t, calculator.PaymentCalendar().BusinessDay(holiday))
+
+	require.NoError(t, configureCalendar(configPath, utils.CalendarConfig{}))
+	assert.Nil(t, calculator.PaymentCalendar())
+
+	assert.Error(t, configureCalendar(configPath, utils.CalendarConfig{Path: "missing.yml"}))
+}
+
+func TestConfigureKeyRates(t *testing.T) {
+	defer calculator.SetKeyRates(nil)
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key_rate.yml"), []byte("rates:\n  - date: 2025-07-28\n    rate: 18\n"), 0o600))
+
+	require.NoError(t, configureKeyRates(configPath, utils.KeyRateConfig{Path: "key_rate.yml"}))
+	rate, ok := calculator.KeyRates().Rate(time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, "18", rate.String())
+
+	require.NoError(t, configureKeyRates(configPath, utils.KeyRateConfig{}))
+	assert.Nil(t, calculator.KeyRates())
+
+	assert.Error(t, configureKeyRates(configPath, utils.KeyRateConfig{Path: "missing.yml"}))
+}
+
+// TestShippedConfig applies the configuration files of the release image, so a broken file fails the build.
+func TestShippedConfig(t *testing.T) {
+	programs, rounding := calculator.Programs(), calculator.Rounding()
+	affordability, batch := calculator.AffordabilityLimits(), calculator.BatchLimits()
+	t.Cleanup(func() {
+		assert.NoError(t, calculator.SetPrograms(programs))
+		assert.NoError(t, calculator.SetRoundingMode(rounding))
+		assert.NoError(t, calculator.SetAffordabilityLimits(affordability))
+		assert.NoError(t, calculator.SetBatchLimits(batch))
+		calculator.SetCacheLimits(cache.Limits{})
+		calculator.SetCalendar(nil)
+		calculator.SetKeyRates(nil)
+		assert.NoError(t, i18n.SetCatalog(nil))
+	})
+
+	configPath := filepath.Join("..", "..", "config", "config.yml")
+	config, err := utils.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	_, err = logging.New(io.Discard, config.Logging.Format, config.Logging.Level)
+	require.NoError(t, err)
+	require.NoError(t, configureCalculator(config))
+
+	// A missing catalogue is allowed by configureMessages, the shipped one must exist.
+	_, err = i18n.LoadFile(filepath.Join(filepath.Dir(configPath), messagesFile))
+	require.NoError(t, err)
+	require.NoError(t, configureMessages(configPath))
+
+	require.NotEmpty(t, config.Calendar.Path)
+	require.NoError(t, configureCalendar(configPath, config.Calendar))
+	assert.NotNil(t, calculator.PaymentCalendar())
+
+	require.NotEmpty(t, config.KeyRate.Path)
+	require.NoError(t, configureKeyRates(configPath, config.KeyRate))
+	assert.NotNil(t, calculator.KeyRates())
+}
+
+func TestNewHandlerUnmatchedRoutes(t *testing.T) {
+	handler := newHandler()
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{method: http.MethodGet, path: "/unknown", status: http.StatusNotFound},
+		{method: http.MethodPut, path: "/execute", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(t, tc.status, rec.Code, tc.path)
+		assert.NotEmpty(t, rec.Header().Get("X-Request-ID"), tc.path)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `http_requests_total{route="unmatched",method="GET",status="404"}`)
+	assert.Contains(t, rec.Body.String(), `http_requests_total{route="unmatched",method="PUT",status="405"}`)
+}
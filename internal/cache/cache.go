@@ -0,0 +1,206 @@
+// Package cache implements a bounded in-memory cache with LRU eviction and per-entry TTL.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Limits bounds the cache, zero values mean no limit.
+type Limits struct {
+	MaxEntries int           `yaml:"max_entries"` // Maximum number of entries.
+	TTL        time.Duration `yaml:"ttl"`         // Lifetime of an entry.
+}
+
+// Stats holds the cache counters.
+type Stats struct {
+	Hits      uint64 `json:"hits"`      // Lookups that found an entry.
+	Misses    uint64 `json:"misses"`    // Lookups that found nothing.
+	Evictions uint64 `json:"evictions"` // Entries removed by the size limit or expired by TTL.
+	Size      int    `json:"size"`      // Current number of entries.
+}
+
+// entry is a cached value with its expiration time, linked in the usage order.
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	expires    time.Time
+	prev, next *entry[K, V]
+}
+
+// LRU is a cache that evicts the least recently used entries when it is full.
+type LRU[K comparable, V any] struct {
+	mu     sync.Mutex
+	limits Limits
+	items  map[K]*entry[K, V]
+	root   entry[K, V] // Sentinel of the usage order, root.next is the most recently used entry.
+	stats  Stats
+	now    func() time.Time
+}
+
+// New creates an empty cache with the given limits.
+func New[K comparable, V any](limits Limits) *LRU[K, V] {
+	c := &LRU[K, V]{
+		limits: limits,
+		items:  make(map[K]*entry[K, V]),
+		now:    time.Now,
+	}
+	c.root.prev, c.root.next = &c.root, &c.root
+	return c
+}
+
+// Get returns the value stored under the key and marks it as recently used.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.lookup(key)
+	if !ok {
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+
+	c.stats.Hits++
+	c.moveToFront(item)
+	return item.value, true
+}
+
+// Contains reports whether the key is cached without touching the counters or the usage order.
+func (c *LRU[K, V]) Contains(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.lookup(key)
+	return ok
+}
+
+// Set stores the value under the key, evicting the least recently used entries if the cache is full.
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if c.limits.TTL > 0 {
+		expires = c.now().Add(c.limits.TTL)
+	}
+
+	if item, ok := c.items[key]; ok {
+		item.value, item.expires = value, expires
+		c.moveToFront(item)
+		return
+	}
+
+	item := &entry[K, V]{key: key, value: value, expires: expires}
+	c.items[key] = item
+	c.moveToFront(item)
+	for c.limits.MaxEntries > 0 && len(c.items) > c.limits.MaxEntries {
+		c.remove(c.root.prev)
+		c.stats.Evictions++
+	}
+}
+
+// Delete removes the key and reports whether it was cached.
+func (c *LRU[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.lookup(key)
+	if ok {
+		c.remove(item)
+	}
+	return ok
+}
+
+// Values returns the values that have not expired, from the most to the least recently used.
+func (c *LRU[K, V]) Values() []V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.purgeExpired()
+	values := make([]V, 0, len(c.items))
+	for item := c.root.next; item != &c.root; item = item.next {
+		values = append(values, item.value)
+	}
+	return values
+}
+
+// Len returns the number of entries that have not expired.
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.purgeExpired()
+	return len(c.items)
+}
+
+// Clear removes all entries keeping the counters.
+func (c *LRU[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[K]*entry[K, V])
+	c.root.prev, c.root.next = &c.root, &c.root
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.purgeExpired()
+	stats := c.stats
+	stats.Size = len(c.items)
+	return stats
+}
+
+// lookup finds the entry, dropping it if it has expired.
+func (c *LRU[K, V]) lookup(key K) (*entry[K, V], bool) {
+	item, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	if c.expired(item) {
+		c.remove(item)
+		c.stats.Evictions++
+		return nil, false
+	}
+	return item, true
+}
+
+// purgeExpired drops all expired entries.
+func (c *LRU[K, V]) purgeExpired() {
+	if c.limits.TTL <= 0 {
+		return
+	}
+	for item := c.root.next; item != &c.root; {
+		next := item.next
+		if c.expired(item) {
+			c.remove(item)
+			c.stats.Evictions++
+		}
+		item = next
+	}
+}
+
+// expired reports whether the entry lifetime is over.
+func (c *LRU[K, V]) expired(item *entry[K, V]) bool {
+	return !item.expires.IsZero() && !c.now().Before(item.expires)
+}
+
+// moveToFront makes the entry the most recently used one.
+func (c *LRU[K, V]) moveToFront(item *entry[K, V]) {
+	if item.prev != nil {
+		item.prev.next, item.next.prev = item.next, item.prev
+	}
+	item.prev, item.next = &c.root, c.root.next
+	c.root.next.prev = item
+	c.root.next = item
+}
+
+// remove deletes the entry from the index and the usage order.
+func (c *LRU[K, V]) remove(item *entry[K, V]) {
+	delete(c.items, item.key)
+	item.prev.next, item.next.prev = item.next, item.prev
+	item.prev, item.next = nil, nil
+}
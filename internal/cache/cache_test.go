@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_Eviction(t *testing.T) {
+	c := New[int, string](Limits{MaxEntries: 2})
+	c.Set(1, "one")
+	c.Set(2, "two")
+
+	// Touch 1 so that 2 becomes the least recently used entry.
+	value, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "one", value)
+
+	c.Set(3, "three")
+	assert.False(t, c.Contains(2))
+	assert.True(t, c.Contains(1))
+	assert.True(t, c.Contains(3))
+	assert.Equal(t, []string{"three", "one"}, c.Values())
+
+	_, ok = c.Get(2)
+	assert.False(t, ok)
+	assert.Equal(t, Stats{Hits: 1, Misses: 1, Evictions: 1, Size: 2}, c.Stats())
+}
+
+func TestLRU_Update(t *testing.T) {
+	c := New[string, int](Limits{MaxEntries: 2})
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("a", 10)
+	c.Set("c", 3)
+
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 10, value)
+	assert.False(t, c.Contains("b"))
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRU_TTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New[int, int](Limits{TTL: time.Minute})
+	c.now = func() time.Time { return now }
+
+	c.Set(1, 1)
+	now = now.Add(30 * time.Second)
+	c.Set(2, 2)
+
+	_, ok := c.Get(1)
+	assert.True(t, ok)
+
+	now = now.Add(30 * time.Second)
+	_, ok = c.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, []int{2}, c.Values())
+
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, Stats{Hits: 1, Misses: 1, Evictions: 2, Size: 0}, c.Stats())
+}
+
+func TestLRU_DeleteAndClear(t *testing.T) {
+	c := New[int, int](Limits{})
+	for i := 0; i < 10; i++ {
+		c.Set(i, i)
+	}
+	assert.Equal(t, 10, c.Len())
+
+	assert.True(t, c.Delete(5))
+	assert.False(t, c.Delete(5))
+	assert.Equal(t, 9, c.Len())
+
+	c.Clear()
+	assert.Equal(t, 0, c.Len())
+	assert.Empty(t, c.Values())
+
+	c.Set(1, 1)
+	assert.Equal(t, []int{1}, c.Values())
+}
@@ -0,0 +1,90 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+// ErrInvalidAffordabilityLimits is returned for inconsistent debt-to-income thresholds.
+var ErrInvalidAffordabilityLimits = errors.New("invalid affordability limits")
+
+var (
+	affordabilityMu     sync.RWMutex
+	affordabilityLimits = defaultAffordabilityLimits()
+)
+
+// defaultAffordabilityLimits returns the thresholds used when the configuration does not define them.
+func defaultAffordabilityLimits() models.AffordabilityLimits {
+	return models.AffordabilityLimits{
+		ApprovePDN:         decimal.NewFromInt(50),
+		MaxPDN:             decimal.NewFromInt(80),
+		DependantAllowance: 15000,
+	}
+}
+
+// SetAffordabilityLimits replaces the debt-to-income thresholds of the affordability check.
+func SetAffordabilityLimits(limits models.AffordabilityLimits) error {
+	switch {
+	case !limits.ApprovePDN.IsPositive():
+		return fmt.Errorf("%w: approval threshold should be positive", ErrInvalidAffordabilityLimits)
+	case limits.MaxPDN.LessThan(limits.ApprovePDN):
+		return fmt.Errorf("%w: maximum threshold is less than approval threshold", ErrInvalidAffordabilityLimits)
+	case limits.DependantAllowance < 0:
+		return fmt.Errorf("%w: negative dependant allowance", ErrInvalidAffordabilityLimits)
+	}
+
+	affordabilityMu.Lock()
+	affordabilityLimits = limits
+	affordabilityMu.Unlock()
+	return nil
+}
+
+// AffordabilityLimits returns the debt-to-income thresholds of the affordability check.
+func AffordabilityLimits() models.AffordabilityLimits {
+	affordabilityMu.RLock()
+	defer affordabilityMu.RUnlock()
+
+	return affordabilityLimits
+}
+
+// CalculateAffordability computes the debt-to-income ratio (PDN) of the borrower with the monthly payment:
+// PDN = (obligations + payment) / (income - dependants * allowance) * 100.
+// The affordability is returned together with ErrDebtLoadTooHigh when the verdict is rejected.
+func CalculateAffordability(borrower models.Borrower, monthlyPayment models.Money) (models.Affordability, error) {
+	if borrower.Income <= 0 || borrower.Obligations < 0 || borrower.Dependants < 0 {
+		return models.Affordability{}, ErrInvalidBorrower
+	}
+
+	limits := AffordabilityLimits()
+	hundred := decimal.NewFromInt(100)
+	obligations := decimal.NewFromInt(int64(borrower.Obligations))
+
+	// Income left after the living costs of the dependants.
+	income := decimal.NewFromInt(int64(borrower.Income - borrower.Dependants*limits.DependantAllowance))
+	if !income.IsPositive() {
+		return models.Affordability{Verdict: models.VerdictRejected, MaxPayment: models.MoneyFromInt(0)}, ErrDebtLoadTooHigh
+	}
+
+	pdn := obligations.Add(monthlyPayment.Decimal).Mul(hundred).Div(income).Round(2)
+	maxPayment := decimal.Max(income.Mul(limits.MaxPDN).Div(hundred).Sub(obligations).RoundDown(2), decimal.Zero)
+
+	affordability := models.Affordability{
+		PDN:        pdn,
+		MaxPayment: models.NewMoney(maxPayment),
+		Verdict:    models.VerdictApproved,
+	}
+
+	switch {
+	case pdn.GreaterThan(limits.MaxPDN):
+		affordability.Verdict = models.VerdictRejected
+		return affordability, fmt.Errorf("%w: %s%% with the maximum payment %s", ErrDebtLoadTooHigh, pdn.StringFixed(2), maxPayment.StringFixed(2))
+	case pdn.GreaterThan(limits.ApprovePDN):
+		affordability.Verdict = models.VerdictReview
+	}
+	return affordability, nil
+}
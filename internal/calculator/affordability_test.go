@@ -0,0 +1,148 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+func TestCalculateAffordability(t *testing.T) {
+	payment := models.NewMoney(decimal.RequireFromString("33457.60"))
+
+	tests := []struct {
+		name               string
+		borrower           models.Borrower
+		expectedPDN        string
+		expectedMaxPayment string
+		expectedVerdict    string
+		expectedErr        error
+	}{
+		{
+			name:               "Approved",
+			borrower:           models.Borrower{Income: 100000, Obligations: 10000},
+			expectedPDN:        "43.46",
+			expectedMaxPayment: "70000.00",
+			expectedVerdict:    models.VerdictApproved,
+		},
+		{
+			name:               "Review with dependants",
+			borrower:           models.Borrower{Income: 100000, Obligations: 10000, Dependants: 2},
+			expectedPDN:        "62.08",
+			expectedMaxPayment: "46000.00",
+			expectedVerdict:    models.VerdictReview,
+		},
+		{
+			name:               "Rejected",
+			borrower:           models.Borrower{Income: 50000, Obligations: 10000},
+			expectedPDN:        "86.92",
+			expectedMaxPayment: "30000.00",
+			expectedVerdict:    models.VerdictRejected,
+			expectedErr:        ErrDebtLoadTooHigh,
+		},
+		{
+			name:               "Obligations above the limit",
+			borrower:           models.Borrower{Income: 50000, Obligations: 45000},
+			expectedPDN:        "156.92",
+			expectedMaxPayment: "0.00",
+			expectedVerdict:    models.VerdictRejected,
+			expectedErr:        ErrDebtLoadTooHigh,
+		},
+		{
+			name:               "Income spent on dependants",
+			borrower:           models.Borrower{Income: 60000, Dependants: 4},
+			expectedPDN:        "0",
+			expectedMaxPayment: "0.00",
+			expectedVerdict:    models.VerdictRejected,
+			expectedErr:        ErrDebtLoadTooHigh,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			affordability, err := CalculateAffordability(tc.borrower, payment)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedPDN, affordability.PDN.String())
+			assert.Equal(t, tc.expectedMaxPayment, affordability.MaxPayment.StringFixed(2))
+			assert.Equal(t, tc.expectedVerdict, affordability.Verdict)
+		})
+	}
+}
+
+func TestCalculateAffordabilityInvalidBorrower(t *testing.T) {
+	payment := models.MoneyFromInt(30000)
+
+	for _, borrower := range []models.Borrower{
+		{},
+		{Income: 100000, Obligations: -1},
+		{Income: 100000, Dependants: -1},
+	} {
+		_, err := CalculateAffordability(borrower, payment)
+		assert.ErrorIs(t, err, ErrInvalidBorrower)
+	}
+}
+
+func TestSetAffordabilityLimits(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, SetAffordabilityLimits(defaultAffordabilityLimits()))
+	})
+
+	invalid := []models.AffordabilityLimits{
+		{MaxPDN: decimal.NewFromInt(80)},
+		{ApprovePDN: decimal.NewFromInt(50), MaxPDN: decimal.NewFromInt(40)},
+		{ApprovePDN: decimal.NewFromInt(50), MaxPDN: decimal.NewFromInt(80), DependantAllowance: -1},
+	}
+	for _, limits := range invalid {
+		assert.ErrorIs(t, SetAffordabilityLimits(limits), ErrInvalidAffordabilityLimits)
+	}
+
+	strict := models.AffordabilityLimits{ApprovePDN: decimal.NewFromInt(30), MaxPDN: decimal.NewFromInt(40)}
+	assert.NoError(t, SetAffordabilityLimits(strict))
+
+	affordability, err := CalculateAffordability(models.Borrower{Income: 100000, Obligations: 10000}, models.MoneyFromInt(25000))
+	assert.NoError(t, err)
+	assert.Equal(t, models.VerdictReview, affordability.Verdict)
+	assert.Equal(t, "30000.00", affordability.MaxPayment.StringFixed(2))
+}
+
+func TestCalculateLoanWithBorrower(t *testing.T) {
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{
+			ObjectCost:     5000000,
+			InitialPayment: 1000000,
+			Months:         240,
+		},
+		Program: models.Program{Salary: true},
+	}
+
+	result, err := CalculateLoan(request)
+	assert.NoError(t, err)
+	assert.Nil(t, result.Affordability)
+
+	request.Borrower = &models.Borrower{Income: 100000, Obligations: 10000}
+	result, err = CalculateLoan(request)
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.Affordability) {
+		assert.Equal(t, models.VerdictApproved, result.Affordability.Verdict)
+	}
+	assert.Equal(t, "33457.60", result.Aggregates.MonthlyPayment.StringFixed(2))
+
+	request.Borrower = &models.Borrower{Income: 40000}
+	result, err = CalculateLoan(request)
+	assert.ErrorIs(t, err, ErrDebtLoadTooHigh)
+	if assert.NotNil(t, result.Affordability) {
+		assert.Equal(t, models.VerdictRejected, result.Affordability.Verdict)
+		assert.Equal(t, "32000.00", result.Affordability.MaxPayment.StringFixed(2))
+	}
+
+	request.Borrower = &models.Borrower{Income: 40000, Obligations: -1}
+	result, err = CalculateLoan(request)
+	assert.ErrorIs(t, err, ErrInvalidBorrower)
+	assert.Nil(t, result.Affordability)
+}
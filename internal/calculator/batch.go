@@ -0,0 +1,110 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+// Errors for batch calculations.
+var (
+	ErrEmptyBatch         = errors.New("batch has no requests")
+	ErrBatchTooLarge      = errors.New("batch exceeds the maximum size")
+	ErrInvalidBatchLimits = errors.New("batch limits must not be negative")
+	ErrInvalidRequest     = errors.New("the request has invalid fields")
+)
+
+// BatchValidator checks the fields of a batch request before its calculation, nil for a valid request.
+type BatchValidator func(request models.LoanRequest) []models.FieldError
+
+var (
+	batchMu     sync.RWMutex
+	batchLimits = defaultBatchLimits()
+)
+
+// defaultBatchLimits returns the limits used when the configuration does not define them.
+func defaultBatchLimits() models.BatchLimits {
+	return models.BatchLimits{MaxSize: 10000, Workers: runtime.NumCPU()}
+}
+
+// SetBatchLimits replaces the maximum batch size and the number of workers, zero values keep the defaults.
+func SetBatchLimits(limits models.BatchLimits) error {
+	if limits.MaxSize < 0 || limits.Workers < 0 {
+		return ErrInvalidBatchLimits
+	}
+
+	defaults := defaultBatchLimits()
+	if limits.MaxSize == 0 {
+		limits.MaxSize = defaults.MaxSize
+	}
+	if limits.Workers == 0 {
+		limits.Workers = defaults.Workers
+	}
+
+	batchMu.Lock()
+	batchLimits = limits
+	batchMu.Unlock()
+	return nil
+}
+
+// BatchLimits returns the maximum batch size and the number of workers.
+func BatchLimits() models.BatchLimits {
+	batchMu.RLock()
+	defer batchMu.RUnlock()
+
+	return batchLimits
+}
+
+// CalculateBatch computes the loans with a bounded pool of workers.
+// The items are returned in the order of the requests, each with its result or calculation error.
+// The requests failing the validation are not calculated, validate may be nil to calculate all of them.
+func CalculateBatch(requests []models.LoanRequest, validate BatchValidator) ([]models.BatchItem, error) {
+	limits := BatchLimits()
+	if len(requests) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(requests) > limits.MaxSize {
+		return nil, fmt.Errorf("%w: %d requests", ErrBatchTooLarge, limits.MaxSize)
+	}
+
+	items := make([]models.BatchItem, len(requests))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < min(limits.Workers, len(requests)); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				items[index] = CalculateBatchItem(index, requests[index], validate)
+			}
+		}()
+	}
+
+	for index := range requests {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	return items, nil
+}
+
+// CalculateBatchItem validates and computes a single request of the batch. A request failing the validation
+// gets ErrInvalidRequest with the errors of its fields.
+func CalculateBatchItem(index int, request models.LoanRequest, validate BatchValidator) models.BatchItem {
+	if validate != nil {
+		if fieldErrors := validate(request); len(fieldErrors) > 0 {
+			return models.BatchItem{Index: index, Code: ErrorCode(ErrInvalidRequest), Error: ErrInvalidRequest.Error(), Errors: fieldErrors}
+		}
+	}
+
+	result, err := CalculateLoan(request)
+	if err != nil {
+		return models.BatchItem{Index: index, Code: ErrorCode(err), Error: err.Error()}
+	}
+	return models.BatchItem{Index: index, Result: &result}
+}
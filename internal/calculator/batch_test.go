@@ -0,0 +1,92 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+func TestCalculateBatch(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, SetBatchLimits(defaultBatchLimits()))
+	})
+	assert.NoError(t, SetBatchLimits(models.BatchLimits{Workers: 3}))
+
+	valid := models.LoanRequest{
+		LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240},
+		Program:    models.Program{Salary: true},
+	}
+
+	requests := make([]models.LoanRequest, 0, 100)
+	for i := 0; i < 100; i++ {
+		request := valid
+		request.Months = 120 + i
+		if i%10 == 0 {
+			request.Program = models.Program{}
+		}
+		requests = append(requests, request)
+	}
+
+	items, err := CalculateBatch(requests, nil)
+	assert.NoError(t, err)
+	assert.Len(t, items, len(requests))
+
+	for i, item := range items {
+		assert.Equal(t, i, item.Index)
+		if i%10 == 0 {
+			assert.Nil(t, item.Result)
+			assert.Equal(t, ErrNoProgramSelected.Error(), item.Error)
+			continue
+		}
+
+		expected, err := CalculateMortgageAggregates(requests[i])
+		assert.NoError(t, err)
+		if assert.NotNil(t, item.Result) {
+			assert.Equal(t, requests[i].Months, item.Result.Params.Months)
+			assert.True(t, expected.MonthlyPayment.Equal(item.Result.Aggregates.MonthlyPayment.Decimal))
+		}
+		assert.Empty(t, item.Error)
+	}
+}
+
+func TestCalculateBatchValidation(t *testing.T) {
+	fieldErrors := []models.FieldError{{Field: "months", Code: "too_large", Message: "must not exceed 600"}}
+	validate := func(request models.LoanRequest) []models.FieldError {
+		if request.Months > 600 {
+			return fieldErrors
+		}
+		return nil
+	}
+
+	requests := []models.LoanRequest{
+		{LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 200000}, Program: models.Program{Salary: true}},
+		{LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240}, Program: models.Program{Salary: true}},
+	}
+
+	items, err := CalculateBatch(requests, validate)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BatchItem{Index: 0, Code: "invalid_request", Error: ErrInvalidRequest.Error(), Errors: fieldErrors}, items[0])
+	assert.NotNil(t, items[1].Result)
+}
+
+func TestCalculateBatchLimits(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, SetBatchLimits(defaultBatchLimits()))
+	})
+
+	assert.ErrorIs(t, SetBatchLimits(models.BatchLimits{MaxSize: -1}), ErrInvalidBatchLimits)
+	assert.NoError(t, SetBatchLimits(models.BatchLimits{MaxSize: 2}))
+	assert.Equal(t, defaultBatchLimits().Workers, BatchLimits().Workers)
+
+	_, err := CalculateBatch(nil, nil)
+	assert.ErrorIs(t, err, ErrEmptyBatch)
+
+	_, err = CalculateBatch(make([]models.LoanRequest, 3), nil)
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+
+	items, err := CalculateBatch(make([]models.LoanRequest, 2), nil)
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+}
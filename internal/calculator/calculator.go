@@ -3,95 +3,133 @@ package calculator
 
 import (
 	"errors"
-	"sync"
-	"time"
 
 	"github.com/shopspring/decimal"
 
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/calendar"
 	"sbermortgagecalculator/internal/models"
 )
 
-// Programs mortgage.
-const (
-	CorporateRate = 8
-	MilitaryRate  = 9
-	BaseRate      = 10
-)
-
-var aggregateCache sync.Map
+var aggregateCache = cache.New[models.LoanRequest, models.Aggregates](cache.Limits{})
 
 // Errors for validation.
 var (
 	ErrNoProgramSelected      = errors.New("choose program")
 	ErrMultiplePrograms       = errors.New("choose only 1 program")
-	ErrInitialPaymentTooLow   = errors.New("the initial payment should be more than or equal to 20% of the object cost")
+	ErrUnknownProgram         = errors.New("unknown loan program")
+	ErrInitialPaymentTooLow   = errors.New("the initial payment should be more than or equal to the program minimum")
+	ErrDebtLoadTooHigh        = errors.New("the debt-to-income ratio exceeds the maximum allowed")
+	ErrInvalidBorrower        = errors.New("borrower income should be positive, obligations and dependants not negative")
+	ErrMonthsOutOfRange       = errors.New("loan term in months is out of the program limits")
+	ErrLoanSumOutOfRange      = errors.New("loan sum is out of the program limits")
 	ErrMonthsShouldBePositive = errors.New("loan term in months should be a positive number")
 	ErrLoanSumZeroOrNegative  = errors.New("loan sum must be greater than zero")
 	ErrCalculationError       = errors.New("undefined behavior: division by zero")
+	ErrUnknownPaymentType     = errors.New("payment type should be annuity or differentiated")
 )
 
+// SetCacheLimits replaces the cache of the calculated aggregates with an empty one bounded by the limits.
+func SetCacheLimits(limits cache.Limits) {
+	aggregateCache = cache.New[models.LoanRequest, models.Aggregates](limits)
+}
+
+// CacheStats returns the counters of the calculated aggregates cache.
+func CacheStats() cache.Stats {
+	return aggregateCache.Stats()
+}
+
+// CalculateLoan computes the aggregates of the loan and checks the debt load of the borrower if given.
+// The loan rejected by the check is returned with its affordability together with ErrDebtLoadTooHigh.
+func CalculateLoan(request models.LoanRequest) (models.CalculationResult, error) {
+	aggregates, err := CalculateMortgageAggregates(request)
+	if err != nil {
+		return models.CalculationResult{}, err
+	}
+
+	result := models.CalculationResult{
+		Aggregates:  aggregates,
+		Params:      request.LoanParams,
+		Program:     request.Program,
+		PaymentType: request.PaymentType,
+	}
+	if request.Borrower == nil {
+		return result, nil
+	}
+
+	affordability, err := CalculateAffordability(*request.Borrower, aggregates.MaxPayment)
+	if err != nil && !errors.Is(err, ErrDebtLoadTooHigh) {
+		return result, err
+	}
+	result.Affordability = &affordability
+	return result, err
+}
+
 // CalculateMortgageAggregates computes the loan parameters (rate, loan amount, monthly payment, overpayment, etc.).
 func CalculateMortgageAggregates(request models.LoanRequest) (models.Aggregates, error) {
-	if err := validateRequest(request); err != nil {
+	program, loanSum, loanMonths, err := prepareLoan(request)
+	if err != nil {
 		return models.Aggregates{}, err
 	}
 
-	rate, err := selectRate(request.Program)
+	issue, err := issueDate(request.IssueDate)
 	if err != nil {
 		return models.Aggregates{}, err
 	}
 
-	// Convert inputs to decimal.
-	objectCost := decimal.NewFromInt(int64(request.ObjectCost))
-	initialPayment := decimal.NewFromInt(int64(request.InitialPayment))
-	loanSum := objectCost.Sub(initialPayment)
-
-	// Making sure that the borrower needs the money.
-	if loanSum.LessThanOrEqual(decimal.Zero) {
-		return models.Aggregates{}, ErrLoanSumZeroOrNegative
-	}
+	// The borrower does not affect the aggregates, the last payment date depends on the actual issue date.
+	key := request
+	key.Borrower = nil
+	key.IssueDate = issue.Format(calendar.DateLayout)
 
-	// Ensure the number of loanMonths is positive.
-	loanMonths := decimal.NewFromInt(int64(request.Months))
-	if loanMonths.LessThanOrEqual(decimal.Zero) {
-		return models.Aggregates{}, ErrMonthsShouldBePositive
+	if aggregate, ok := aggregateCache.Get(key); ok {
+		return aggregate, nil
 	}
 
-	aggregateAny, ok := aggregateCache.Load(request)
-	if ok {
-		if aggregate, ok := aggregateAny.(models.Aggregates); ok {
-			aggregate.LastPaymentDate = time.Now().AddDate(0, int(loanMonths.IntPart()), 0).Format("2006-01-02")
-			return aggregate, nil
-		}
+	rates := newRateSchedule(program, issue, request.PaymentDay)
+	aggregate, err := calculateScheduleAggregates(loanSum, rates, int(loanMonths.IntPart()), request.PaymentType)
+	if err != nil {
+		return models.Aggregates{}, err
 	}
 
-	// Monthly interest rate in decimal form: rate / 100 / 12.
-	monthlyRate := decimal.NewFromInt(int64(rate)).Div(decimal.NewFromInt(100)).Div(decimal.NewFromInt(12))
+	aggregateCache.Set(key, aggregate)
+	return aggregate, nil
+}
 
-	// Calculate the monthly payment (annuity formula - docs example_golang.xlsx).
-	monthlyPayment, err := calculateMonthlyPayment(loanSum, monthlyRate, loanMonths)
+// calculateScheduleAggregates computes the aggregates of a loan from its schedule, so the payments and
+// the overpayment are exactly those of the schedule rows, including the rounding absorbed by the last payment.
+// The payments of every rate period are reported for the stepped and floating rates.
+func calculateScheduleAggregates(loanSum decimal.Decimal, rates rateSchedule, months int, paymentType string) (models.Aggregates, error) {
+	schedule, err := repaymentSchedule(loanSum, rates, months, paymentType, nil)
 	if err != nil {
 		return models.Aggregates{}, err
 	}
+	return scheduleAggregates(schedule, loanSum, rates), nil
+}
 
-	// Total amount of payments for the entire loan period with body and interest.
-	totalPayment := monthlyPayment.Mul(loanMonths)
+// prepareLoan validates the request and returns the program, loan sum and loan term in months.
+func prepareLoan(request models.LoanRequest) (models.LoanProgram, decimal.Decimal, decimal.Decimal, error) {
+	program, err := selectProgram(request.Program)
+	if err != nil {
+		return models.LoanProgram{}, decimal.Zero, decimal.Zero, err
+	}
 
-	// Interest for using the bank's money.
-	overpayment := totalPayment.Sub(loanSum)
+	if err = validateRequest(request, program); err != nil {
+		return models.LoanProgram{}, decimal.Zero, decimal.Zero, err
+	}
+
+	// Convert inputs to decimal.
+	objectCost := decimal.NewFromInt(int64(request.ObjectCost))
+	initialPayment := decimal.NewFromInt(int64(request.InitialPayment))
+	loanSum := objectCost.Sub(initialPayment)
+	loanMonths := decimal.NewFromInt(int64(request.Months))
 
-	// Last payment date.
-	lastPaymentDate := time.Now().AddDate(0, int(loanMonths.IntPart()), 0).Format("2006-01-02")
+	return program, loanSum, loanMonths, nil
+}
 
-	aggregate := models.Aggregates{
-		Rate:            rate,
-		LoanSum:         int(loanSum.IntPart()),
-		MonthlyPayment:  int(monthlyPayment.IntPart()),
-		Overpayment:     int(overpayment.IntPart()),
-		LastPaymentDate: lastPaymentDate,
-	}
-	aggregateCache.Store(request, aggregate)
-	return aggregate, nil
+// calculateMonthlyRate converts the annual rate in percent to the monthly rate in decimal form: rate / 100 / 12.
+func calculateMonthlyRate(rate decimal.Decimal) decimal.Decimal {
+	return rate.Div(decimal.NewFromInt(100)).Div(decimal.NewFromInt(12))
 }
 
 // calculateMonthlyPayment computes the monthly payment using the annuity formula.
@@ -125,32 +163,71 @@ func calculateMonthlyPayment(loanSum, monthlyRate, months decimal.Decimal) (deci
 	return numerator.Div(denominator), nil
 }
 
-// selectRate determines the loan's interest rate based on the selected program.
-func selectRate(program models.Program) (int, error) {
-	if program.Salary && !program.Military && !program.Base {
-		return CorporateRate, nil
-	}
-	if program.Military && !program.Salary && !program.Base {
-		return MilitaryRate, nil
+// selectProgram determines the configured loan program selected in the request.
+func selectProgram(program models.Program) (models.LoanProgram, error) {
+	var selected []string
+	if program.ID != "" {
+		selected = append(selected, program.ID)
 	}
-	if program.Base && !program.Salary && !program.Military {
-		return BaseRate, nil
+	for id, flag := range map[string]bool{
+		SalaryProgramID:   program.Salary,
+		MilitaryProgramID: program.Military,
+		BaseProgramID:     program.Base,
+	} {
+		if flag && id != program.ID {
+			selected = append(selected, id)
+		}
 	}
 
 	// Validate program selection
-	if !program.Salary && !program.Military && !program.Base {
-		return 0, ErrNoProgramSelected
+	switch len(selected) {
+	case 0:
+		return models.LoanProgram{}, ErrNoProgramSelected
+	case 1:
+	default:
+		return models.LoanProgram{}, ErrMultiplePrograms
+	}
+
+	loanProgram, ok := findProgram(selected[0])
+	if !ok {
+		return models.LoanProgram{}, ErrUnknownProgram
 	}
-	return 0, ErrMultiplePrograms
+	return loanProgram, nil
 }
 
-// validateRequest validates the loan request parameters. Ensures initial payment, programs, and loan terms are valid.
-func validateRequest(request models.LoanRequest) error {
-	minInitialPayment := decimal.NewFromInt(int64(request.ObjectCost)).Mul(decimal.NewFromFloat(0.2))
+// validateRequest validates the loan request parameters against the program conditions.
+// Ensures initial payment, payment type, loan sum and loan terms are valid.
+func validateRequest(request models.LoanRequest, program models.LoanProgram) error {
+	objectCost := decimal.NewFromInt(int64(request.ObjectCost))
+	minInitialPayment := objectCost.Mul(program.MinInitialPercent).Div(decimal.NewFromInt(100))
 	initialPayment := decimal.NewFromInt(int64(request.InitialPayment))
 
 	if initialPayment.LessThan(minInitialPayment) {
 		return ErrInitialPaymentTooLow
 	}
+
+	switch request.PaymentType {
+	case "", models.PaymentTypeAnnuity, models.PaymentTypeDifferentiated:
+	default:
+		return ErrUnknownPaymentType
+	}
+
+	// Making sure that the borrower needs the money.
+	loanSum := request.ObjectCost - request.InitialPayment
+	if loanSum <= 0 {
+		return ErrLoanSumZeroOrNegative
+	}
+
+	// Ensure the number of months is positive.
+	if request.Months <= 0 {
+		return ErrMonthsShouldBePositive
+	}
+
+	if request.Months < program.MinMonths || (program.MaxMonths > 0 && request.Months > program.MaxMonths) {
+		return ErrMonthsOutOfRange
+	}
+	if loanSum < program.MinLoanSum || (program.MaxLoanSum > 0 && loanSum > program.MaxLoanSum) {
+		return ErrLoanSumOutOfRange
+	}
 	return nil
 }
@@ -2,10 +2,13 @@ package calculator
 
 import (
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/calendar"
 	"sbermortgagecalculator/internal/models"
 )
 
@@ -13,9 +16,9 @@ func TestCalculateMortgageAggregates(t *testing.T) {
 	tests := []struct {
 		name            string
 		request         models.LoanRequest
-		expectedRate    int
-		expectedLoan    int
-		expectedPayment int
+		expectedRate    string
+		expectedLoan    string
+		expectedPayment string
 		expectErr       error
 	}{
 		{
@@ -28,9 +31,9 @@ func TestCalculateMortgageAggregates(t *testing.T) {
 				},
 				Program: models.Program{Salary: true},
 			},
-			expectedRate:    CorporateRate,
-			expectedLoan:    4000000,
-			expectedPayment: 33457,
+			expectedRate:    "8",
+			expectedLoan:    "4000000.00",
+			expectedPayment: "33457.60",
 			expectErr:       nil,
 		},
 		{
@@ -43,9 +46,9 @@ func TestCalculateMortgageAggregates(t *testing.T) {
 				},
 				Program: models.Program{Military: true},
 			},
-			expectedRate:    MilitaryRate,
-			expectedLoan:    2400000,
-			expectedPayment: 24342,
+			expectedRate:    "9",
+			expectedLoan:    "2400000.00",
+			expectedPayment: "24342.40",
 			expectErr:       nil,
 		},
 		{
@@ -58,9 +61,9 @@ func TestCalculateMortgageAggregates(t *testing.T) {
 				},
 				Program: models.Program{Base: true},
 			},
-			expectedRate:    BaseRate,
-			expectedLoan:    2400000,
-			expectedPayment: 25790,
+			expectedRate:    "10",
+			expectedLoan:    "2400000.00",
+			expectedPayment: "25790.52",
 			expectErr:       nil,
 		},
 		{
@@ -144,6 +147,19 @@ func TestCalculateMortgageAggregates(t *testing.T) {
 			},
 			expectErr: ErrLoanSumZeroOrNegative,
 		},
+		{
+			name: "Unknown payment type",
+			request: models.LoanRequest{
+				LoanParams: models.LoanParams{
+					ObjectCost:     5000000,
+					InitialPayment: 1000000,
+					Months:         240,
+				},
+				Program:     models.Program{Salary: true},
+				PaymentType: "balloon",
+			},
+			expectErr: ErrUnknownPaymentType,
+		},
 		{
 			name: "Zero Months",
 			request: models.LoanRequest{
@@ -167,9 +183,9 @@ func TestCalculateMortgageAggregates(t *testing.T) {
 			}
 
 			assert.NoError(t, err)
-			assert.Equal(t, tc.expectedRate, result.Rate)
-			assert.Equal(t, tc.expectedLoan, result.LoanSum)
-			assert.Equal(t, tc.expectedPayment, result.MonthlyPayment)
+			assert.Equal(t, tc.expectedRate, result.Rate.String())
+			assert.Equal(t, tc.expectedLoan, result.LoanSum.StringFixed(2))
+			assert.Equal(t, tc.expectedPayment, result.MonthlyPayment.StringFixed(2))
 		})
 	}
 }
@@ -190,6 +206,149 @@ func TestCalculateMortgageAggregatesCached(t *testing.T) {
 	assert.Equal(t, resultFirst, resutlSecond)
 }
 
+func TestCalculateMortgageAggregatesCacheLimits(t *testing.T) {
+	SetCacheLimits(cache.Limits{MaxEntries: 2})
+	t.Cleanup(func() {
+		SetCacheLimits(cache.Limits{})
+	})
+
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{
+			ObjectCost:     5000000,
+			InitialPayment: 1000000,
+		},
+		Program: models.Program{Salary: true},
+	}
+	for _, months := range []int{120, 180, 240, 120} {
+		request.Months = months
+		_, err := CalculateMortgageAggregates(request)
+		assert.NoError(t, err)
+	}
+
+	// The first request was evicted by the third one, so repeating it misses.
+	assert.Equal(t, cache.Stats{Hits: 0, Misses: 4, Evictions: 2, Size: 2}, CacheStats())
+
+	request.Months = 240
+	_, err := CalculateMortgageAggregates(request)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), CacheStats().Hits)
+}
+
+func TestCalculateMortgageAggregatesRounding(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, SetRoundingMode(models.RoundingHalfUp))
+	})
+
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{
+			ObjectCost:     5000000,
+			InitialPayment: 1000000,
+			Months:         240,
+		},
+		Program: models.Program{Salary: true},
+	}
+
+	tests := []struct {
+		mode                models.RoundingMode
+		expectedPayment     string
+		expectedOverpayment string
+		expectedV1Payment   int
+	}{
+		{mode: models.RoundingHalfUp, expectedPayment: "33457.60", expectedOverpayment: "4029825.57", expectedV1Payment: 33458},
+		{mode: models.RoundingBankers, expectedPayment: "33457.60", expectedOverpayment: "4029825.57", expectedV1Payment: 33458},
+		{mode: models.RoundingCeilRuble, expectedPayment: "33458.00", expectedOverpayment: "4029974.00", expectedV1Payment: 33458},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			assert.NoError(t, SetRoundingMode(tc.mode))
+			assert.Equal(t, tc.mode, Rounding())
+
+			result, err := CalculateMortgageAggregates(request)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPayment, result.MonthlyPayment.StringFixed(2))
+			assert.Equal(t, tc.expectedOverpayment, result.Overpayment.StringFixed(2))
+			assert.Equal(t, tc.expectedV1Payment, result.V1(tc.mode).MonthlyPayment)
+		})
+	}
+
+	assert.ErrorIs(t, SetRoundingMode("floor"), ErrUnknownRoundingMode)
+}
+
+func TestCalculateMortgageAggregatesFractionalRate(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, SetPrograms(defaultPrograms()))
+	})
+
+	subsidised := defaultPrograms()[0]
+	subsidised.Rate = decimal.RequireFromString("7.4")
+	assert.NoError(t, SetPrograms([]models.LoanProgram{subsidised}))
+
+	result, err := CalculateMortgageAggregates(models.LoanRequest{
+		LoanParams: models.LoanParams{
+			ObjectCost:     5000000,
+			InitialPayment: 1000000,
+			Months:         240,
+		},
+		Program: models.Program{Salary: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "7.4", result.Rate.String())
+	assert.Equal(t, "31979.58", result.MonthlyPayment.StringFixed(2))
+}
+
+func TestCalculateMortgageAggregatesDifferentiated(t *testing.T) {
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{
+			ObjectCost:     5000000,
+			InitialPayment: 1000000,
+			Months:         240,
+		},
+		Program:     models.Program{Salary: true},
+		PaymentType: models.PaymentTypeDifferentiated,
+	}
+
+	result, err := CalculateMortgageAggregates(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "8", result.Rate.String())
+	assert.Equal(t, "4000000.00", result.LoanSum.StringFixed(2))
+	assert.Equal(t, "43333.34", result.FirstPayment.StringFixed(2))
+	assert.Equal(t, "16776.98", result.LastPayment.StringFixed(2))
+	assert.Equal(t, "43333.34", result.MaxPayment.StringFixed(2))
+	assert.Equal(t, result.FirstPayment, result.MonthlyPayment)
+	assert.Equal(t, "3213332.71", result.Overpayment.StringFixed(2))
+}
+
+func TestCalculateMortgageAggregatesMatchSchedule(t *testing.T) {
+	for _, paymentType := range []string{models.PaymentTypeAnnuity, models.PaymentTypeDifferentiated} {
+		t.Run(paymentType, func(t *testing.T) {
+			request := models.LoanRequest{
+				LoanParams: models.LoanParams{
+					ObjectCost:     5000000,
+					InitialPayment: 1000000,
+					Months:         240,
+				},
+				Program:     models.Program{Salary: true},
+				PaymentType: paymentType,
+			}
+
+			result, err := CalculateMortgageAggregates(request)
+			assert.NoError(t, err)
+			schedule, err := CalculatePaymentSchedule(request)
+			assert.NoError(t, err)
+
+			interest := decimal.Zero
+			for _, row := range schedule {
+				interest = interest.Add(row.Interest.Decimal)
+			}
+			last := schedule[len(schedule)-1]
+			assert.Equal(t, interest.StringFixed(2), result.Overpayment.StringFixed(2))
+			assert.Equal(t, last.Payment.StringFixed(2), result.LastPayment.StringFixed(2))
+			assert.Equal(t, last.Date, result.LastPaymentDate)
+		})
+	}
+}
+
 func TestCalculateMonthlyPayment(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -202,7 +361,7 @@ func TestCalculateMonthlyPayment(t *testing.T) {
 		{
 			name:           "Standard case with 8% annual interest rate",
 			loanSum:        decimal.NewFromInt(4000000),
-			monthlyRate:    decimal.NewFromInt32(CorporateRate).Div(decimal.NewFromInt(100)).Div(decimal.NewFromInt(12)),
+			monthlyRate:    decimal.NewFromInt(8).Div(decimal.NewFromInt(100)).Div(decimal.NewFromInt(12)),
 			months:         decimal.NewFromInt(20).Mul(decimal.NewFromInt(12)),
 			expectedResult: decimal.RequireFromString("33457.6"),
 			expectErr:      nil,
@@ -226,7 +385,7 @@ func TestCalculateMonthlyPayment(t *testing.T) {
 		{
 			name:           "Zero months (invalid input)",
 			loanSum:        decimal.NewFromInt(4000000),
-			monthlyRate:    decimal.NewFromInt32(CorporateRate).Div(decimal.NewFromInt(100)).Div(decimal.NewFromInt(12)),
+			monthlyRate:    decimal.NewFromInt(8).Div(decimal.NewFromInt(100)).Div(decimal.NewFromInt(12)),
 			months:         decimal.Zero,
 			expectedResult: decimal.Zero,
 			expectErr:      ErrCalculationError,
@@ -267,3 +426,135 @@ func TestCalculateMonthlyPayment(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculatePaymentSchedule(t *testing.T) {
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{
+			ObjectCost:     5000000,
+			InitialPayment: 1000000,
+			Months:         240,
+		},
+		Program:   models.Program{Salary: true},
+		IssueDate: "2025-01-15",
+	}
+
+	schedule, err := CalculatePaymentSchedule(request)
+	assert.NoError(t, err)
+	assert.Len(t, schedule, 240)
+	assert.Equal(t, "2025-02-17", schedule[0].Date, "the due date on Saturday moves to Monday")
+
+	aggregate, err := CalculateMortgageAggregates(request)
+	assert.NoError(t, err)
+
+	principalSum := decimal.Zero
+	for i, row := range schedule {
+		assert.Equal(t, i+1, row.Number)
+		assert.True(t, row.Payment.Equal(row.Interest.Add(row.Principal.Decimal)), "row %d: payment must be interest plus principal", row.Number)
+		principalSum = principalSum.Add(row.Principal.Decimal)
+	}
+
+	assert.True(t, schedule[0].Payment.Equal(decimal.RequireFromString("33457.6")), "got first payment %s", schedule[0].Payment)
+	assert.True(t, schedule[len(schedule)-1].Balance.IsZero())
+	assert.True(t, principalSum.Equal(decimal.NewFromInt(4000000)))
+	assert.Equal(t, "2045-01-16", schedule[len(schedule)-1].Date)
+	assert.Equal(t, aggregate.LastPaymentDate, schedule[len(schedule)-1].Date)
+}
+
+func TestCalculatePaymentScheduleBusinessDays(t *testing.T) {
+	production, err := calendar.New([]string{"2025-03-31"}, nil)
+	assert.NoError(t, err)
+	SetCalendar(production)
+	defer SetCalendar(nil)
+
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 6},
+		Program:    models.Program{Salary: true},
+		PaymentDay: 31,
+		IssueDate:  "2025-01-15",
+	}
+	schedule, err := CalculatePaymentSchedule(request)
+	assert.NoError(t, err)
+
+	// The 31st moves to the last day of the shorter months, the holiday and the weekend to the next working day.
+	dates := make([]string, 0, len(schedule))
+	for _, row := range schedule {
+		dates = append(dates, row.Date)
+	}
+	assert.Equal(t, []string{"2025-02-28", "2025-04-01", "2025-04-30", "2025-06-02", "2025-06-30", "2025-07-31"}, dates)
+
+	aggregate, err := CalculateMortgageAggregates(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-07-31", aggregate.LastPaymentDate)
+}
+
+func TestCalculateMortgageAggregatesClock(t *testing.T) {
+	now := time.Date(2044, time.December, 15, 23, 30, 0, 0, time.Local)
+	SetClock(ClockFunc(func() time.Time { return now }))
+	defer SetClock(nil)
+
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 1},
+		Program:    models.Program{Salary: true},
+	}
+	aggregate, err := CalculateMortgageAggregates(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "2045-01-16", aggregate.LastPaymentDate, "the due date on Sunday moves to Monday")
+
+	// The cached aggregates of the previous day are not reused.
+	now = now.AddDate(0, 0, 1)
+	aggregate, err = CalculateMortgageAggregates(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "2045-01-16", aggregate.LastPaymentDate)
+
+	now = now.AddDate(0, 0, 3)
+	aggregate, err = CalculateMortgageAggregates(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "2045-01-19", aggregate.LastPaymentDate)
+
+	// The issue date of the request takes precedence over the clock.
+	request.IssueDate = "2025-04-30"
+	aggregate, err = CalculateMortgageAggregates(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-05-30", aggregate.LastPaymentDate)
+
+	request.IssueDate = "30.04.2025"
+	_, err = CalculateMortgageAggregates(request)
+	assert.ErrorIs(t, err, ErrInvalidIssueDate)
+	assert.Equal(t, "invalid_issue_date", ErrorCode(err))
+}
+
+func TestCalculatePaymentScheduleInvalid(t *testing.T) {
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{
+			ObjectCost:     5000000,
+			InitialPayment: 1000000,
+			Months:         240,
+		},
+	}
+
+	schedule, err := CalculatePaymentSchedule(request)
+	assert.ErrorIs(t, err, ErrNoProgramSelected)
+	assert.Nil(t, schedule)
+}
+
+func TestCalculatePaymentScheduleDifferentiated(t *testing.T) {
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{
+			ObjectCost:     3000000,
+			InitialPayment: 600000,
+			Months:         180,
+		},
+		Program:     models.Program{Military: true},
+		PaymentType: models.PaymentTypeDifferentiated,
+	}
+
+	schedule, err := CalculatePaymentSchedule(request)
+	assert.NoError(t, err)
+	assert.Len(t, schedule, 180)
+
+	for i := 1; i < len(schedule); i++ {
+		assert.True(t, schedule[i].Payment.LessThan(schedule[i-1].Payment.Decimal), "payment %d must decline", schedule[i].Number)
+	}
+	assert.True(t, schedule[0].Principal.Equal(decimal.RequireFromString("13333.33")))
+	assert.True(t, schedule[len(schedule)-1].Balance.IsZero())
+}
@@ -0,0 +1,78 @@
+package calculator
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorCodeUnknown is the code of the errors that are not calculation errors.
+const ErrorCodeUnknown = "unknown"
+
+// errorCodes are the stable codes of the calculation errors, checked in order.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrNoProgramSelected, "no_program_selected"},
+	{ErrMultiplePrograms, "multiple_programs"},
+	{ErrUnknownProgram, "unknown_program"},
+	{ErrInitialPaymentTooLow, "initial_payment_too_low"},
+	{ErrDebtLoadTooHigh, "debt_load_too_high"},
+	{ErrInvalidBorrower, "invalid_borrower"},
+	{ErrMonthsOutOfRange, "months_out_of_range"},
+	{ErrLoanSumOutOfRange, "loan_sum_out_of_range"},
+	{ErrMonthsShouldBePositive, "months_not_positive"},
+	{ErrLoanSumZeroOrNegative, "loan_sum_not_positive"},
+	{ErrCalculationError, "division_by_zero"},
+	{ErrUnknownPaymentType, "unknown_payment_type"},
+	{ErrNoEligiblePrograms, "no_eligible_programs"},
+	{ErrEmptyBatch, "empty_batch"},
+	{ErrBatchTooLarge, "batch_too_large"},
+	{ErrInvalidRequest, "invalid_request"},
+	{ErrPrepaymentMonth, "prepayment_month"},
+	{ErrPrepaymentAmount, "prepayment_amount"},
+	{ErrPrepaymentPeriod, "prepayment_period"},
+	{ErrUnknownPrepaymentStrategy, "unknown_prepayment_strategy"},
+	{ErrNoPrepayments, "no_prepayments"},
+	{ErrTargetPaymentZeroOrNegative, "target_payment_not_positive"},
+	{ErrReverseTarget, "reverse_target"},
+	{ErrPaymentBelowInterest, "payment_below_interest"},
+	{ErrProgramNotFinanced, "program_not_financed"},
+	{ErrInvalidIssueDate, "invalid_issue_date"},
+	{ErrNoKeyRate, "no_key_rate"},
+}
+
+// ErrorCode returns the stable code of the calculation error for metrics and messages,
+// ErrorCodeUnknown if the error is not one of the calculation errors.
+func ErrorCode(err error) string {
+	for _, known := range errorCodes {
+		if errors.Is(err, known.err) {
+			return known.code
+		}
+	}
+	return ErrorCodeUnknown
+}
+
+// ErrorDetail returns the details added to the text of the calculation error, such as the actual debt load,
+// or an empty string if there are none or the error is not a calculation error.
+func ErrorDetail(err error) string {
+	if err == nil {
+		return ""
+	}
+	return MessageDetail(ErrorCode(err), err.Error())
+}
+
+// MessageDetail returns the details added to the text of the calculation error with the code,
+// or an empty string if there are none or the code is not of a calculation error.
+func MessageDetail(code, message string) string {
+	for _, known := range errorCodes {
+		if known.code == code {
+			detail, found := strings.CutPrefix(message, known.err.Error()+": ")
+			if !found {
+				return ""
+			}
+			return detail
+		}
+	}
+	return ""
+}
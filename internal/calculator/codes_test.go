@@ -0,0 +1,38 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{ErrNoProgramSelected, "no_program_selected"},
+		{fmt.Errorf("%w: pdn 85%%", ErrDebtLoadTooHigh), "debt_load_too_high"},
+		{ErrBatchTooLarge, "batch_too_large"},
+		{ErrPaymentBelowInterest, "payment_below_interest"},
+		{ErrProgramNotFinanced, "program_not_financed"},
+		{errors.New("other"), ErrorCodeUnknown},
+		{nil, ErrorCodeUnknown},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, ErrorCode(test.err))
+	}
+}
+
+func TestErrorDetail(t *testing.T) {
+	assert.Equal(t, "pdn 85%", ErrorDetail(fmt.Errorf("%w: pdn 85%%", ErrDebtLoadTooHigh)))
+	assert.Empty(t, ErrorDetail(ErrNoProgramSelected))
+	assert.Empty(t, ErrorDetail(fmt.Errorf("batch: %w", ErrEmptyBatch)))
+	assert.Empty(t, ErrorDetail(errors.New("other: detail")))
+
+	assert.Equal(t, "pdn 85%", MessageDetail("debt_load_too_high", "the debt-to-income ratio exceeds the maximum allowed: pdn 85%"))
+	assert.Empty(t, MessageDetail(ErrorCodeUnknown, "other: detail"))
+}
@@ -0,0 +1,72 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+// ErrNoEligiblePrograms is returned when the loan does not qualify for any configured program.
+var ErrNoEligiblePrograms = errors.New("no eligible loan programs")
+
+// ComparePrograms calculates the loan with every configured program and ranks the eligible ones
+// by overpayment, then by monthly payment. Programs whose conditions or debt-to-income thresholds
+// the loan does not meet are listed as ineligible with the reason.
+func ComparePrograms(request models.CompareRequest) (models.CompareResult, error) {
+	result := models.CompareResult{
+		Params:      request.LoanParams,
+		PaymentType: request.PaymentType,
+		Offers:      []models.ProgramOffer{},
+	}
+
+	reasons := make([]string, 0)
+	for _, program := range Programs() {
+		calculation, err := CalculateLoan(models.LoanRequest{
+			LoanParams:  request.LoanParams,
+			Program:     models.Program{ID: program.ID},
+			PaymentType: request.PaymentType,
+			Borrower:    request.Borrower,
+		})
+		if err != nil {
+			result.Ineligible = append(result.Ineligible, models.IneligibleProgram{ProgramID: program.ID, Code: ErrorCode(err), Reason: err.Error()})
+			reasons = append(reasons, fmt.Sprintf("%s: %s", program.ID, err.Error()))
+			continue
+		}
+
+		result.Offers = append(result.Offers, models.ProgramOffer{
+			ProgramID:      program.ID,
+			Name:           program.Name,
+			Rate:           calculation.Aggregates.Rate,
+			MonthlyPayment: calculation.Aggregates.MonthlyPayment,
+			Overpayment:    calculation.Aggregates.Overpayment,
+			Aggregates:     calculation.Aggregates,
+			Affordability:  calculation.Affordability,
+		})
+	}
+
+	if len(result.Offers) == 0 {
+		return models.CompareResult{}, fmt.Errorf("%w: %s", ErrNoEligiblePrograms, strings.Join(reasons, "; "))
+	}
+
+	rankOffers(result.Offers)
+	return result, nil
+}
+
+// rankOffers sorts the offers from the cheapest and computes the differences versus the cheapest one.
+func rankOffers(offers []models.ProgramOffer) {
+	sort.SliceStable(offers, func(i, j int) bool {
+		if !offers[i].Overpayment.Equal(offers[j].Overpayment.Decimal) {
+			return offers[i].Overpayment.LessThan(offers[j].Overpayment.Decimal)
+		}
+		return offers[i].MonthlyPayment.LessThan(offers[j].MonthlyPayment.Decimal)
+	})
+
+	cheapest := offers[0]
+	for i := range offers {
+		offers[i].MonthlyPaymentDelta = models.NewMoney(offers[i].MonthlyPayment.Sub(cheapest.MonthlyPayment.Decimal))
+		offers[i].OverpaymentDelta = models.NewMoney(offers[i].Overpayment.Sub(cheapest.Overpayment.Decimal))
+	}
+}
@@ -0,0 +1,84 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+func TestComparePrograms(t *testing.T) {
+	result, err := ComparePrograms(models.CompareRequest{
+		LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Ineligible)
+
+	var ids, payments, deltas []string
+	for _, offer := range result.Offers {
+		ids = append(ids, offer.ProgramID)
+		payments = append(payments, offer.MonthlyPayment.StringFixed(2))
+		deltas = append(deltas, offer.OverpaymentDelta.StringFixed(2))
+	}
+	assert.Equal(t, []string{SalaryProgramID, MilitaryProgramID, BaseProgramID}, ids)
+	assert.Equal(t, []string{"33457.60", "35989.04", "38600.87"}, payments)
+	assert.Equal(t, []string{"0.00", "607543.04", "1234379.96"}, deltas)
+	assert.Equal(t, "Corporate program", result.Offers[0].Name)
+	assert.Equal(t, "2531.44", result.Offers[1].MonthlyPaymentDelta.StringFixed(2))
+}
+
+func TestCompareProgramsIneligible(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, SetPrograms(defaultPrograms()))
+	})
+
+	family := models.LoanProgram{
+		ID:                "family",
+		Name:              "Family mortgage",
+		Rate:              decimal.RequireFromString("5.95"),
+		MinInitialPercent: decimal.NewFromInt(15),
+		MinMonths:         12,
+		MaxMonths:         360,
+	}
+	assert.NoError(t, SetPrograms(append(defaultPrograms(), family)))
+
+	result, err := ComparePrograms(models.CompareRequest{
+		LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, result.Offers, 4) {
+		assert.Equal(t, "family", result.Offers[0].ProgramID)
+		assert.True(t, result.Offers[3].OverpaymentDelta.IsPositive())
+	}
+
+	result, err = ComparePrograms(models.CompareRequest{
+		LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 400},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, result.Offers, 3)
+	assert.Equal(t, []models.IneligibleProgram{{ProgramID: "family", Code: "months_out_of_range", Reason: ErrMonthsOutOfRange.Error()}}, result.Ineligible)
+}
+
+func TestCompareProgramsBorrower(t *testing.T) {
+	request := models.CompareRequest{
+		LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240},
+		Borrower:   &models.Borrower{Income: 43000},
+	}
+
+	result, err := ComparePrograms(request)
+	assert.NoError(t, err)
+	if assert.Len(t, result.Offers, 1) {
+		assert.Equal(t, SalaryProgramID, result.Offers[0].ProgramID)
+		assert.Equal(t, models.VerdictReview, result.Offers[0].Affordability.Verdict)
+	}
+	assert.Len(t, result.Ineligible, 2)
+
+	request.Borrower = &models.Borrower{Income: 30000}
+	_, err = ComparePrograms(request)
+	assert.ErrorIs(t, err, ErrNoEligiblePrograms)
+
+	_, err = ComparePrograms(models.CompareRequest{})
+	assert.ErrorIs(t, err, ErrNoEligiblePrograms)
+}
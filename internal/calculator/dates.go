@@ -0,0 +1,94 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"sbermortgagecalculator/internal/calendar"
+)
+
+// ErrInvalidIssueDate is returned for an issue date of the request not in the YYYY-MM-DD format.
+var ErrInvalidIssueDate = errors.New("issue date should be in the YYYY-MM-DD format")
+
+// Clock tells the current time, the issue date of the loans without one in the request.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now calls the function.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the wall clock of the calculator by default.
+var SystemClock Clock = ClockFunc(time.Now)
+
+var (
+	calendarMu      sync.RWMutex
+	paymentCalendar *calendar.Calendar // Weekends only by default.
+)
+
+var (
+	clockMu sync.RWMutex
+	clock   = SystemClock
+)
+
+// SetCalendar replaces the production calendar of the payment dates, nil leaves only the weekends as days off.
+// The calculated aggregates are dropped as their last payment dates may change.
+func SetCalendar(production *calendar.Calendar) {
+	calendarMu.Lock()
+	paymentCalendar = production
+	calendarMu.Unlock()
+
+	aggregateCache.Clear()
+}
+
+// PaymentCalendar returns the production calendar of the payment dates.
+func PaymentCalendar() *calendar.Calendar {
+	calendarMu.RLock()
+	defer calendarMu.RUnlock()
+	return paymentCalendar
+}
+
+// SetClock replaces the clock of the issue dates, nil restores SystemClock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = SystemClock
+	}
+
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	clock = c
+}
+
+// issueDate returns the issue date of the request, today by the clock if the request has none.
+func issueDate(value string) (time.Time, error) {
+	if value != "" {
+		date, err := time.Parse(calendar.DateLayout, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidIssueDate, value)
+		}
+		return date, nil
+	}
+
+	clockMu.RLock()
+	now := clock.Now()
+	clockMu.RUnlock()
+
+	// The date of the local time, like the dates of the calendar, has no time of day.
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// paymentDates returns the formatted dates of the payments by their number for the loan issued on the issue date
+// with the payments due on the payment day.
+func paymentDates(issue time.Time, paymentDay int) func(number int) string {
+	production := PaymentCalendar()
+	return func(number int) string {
+		return production.PaymentDate(issue, paymentDay, number).Format(calendar.DateLayout)
+	}
+}
@@ -0,0 +1,121 @@
+package calculator
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+// Errors for prepayment validation.
+var (
+	ErrPrepaymentMonth           = errors.New("prepayment month should be within the loan term")
+	ErrPrepaymentAmount          = errors.New("prepayment amount must be greater than zero")
+	ErrPrepaymentPeriod          = errors.New("prepayment repeat period must not be negative")
+	ErrUnknownPrepaymentStrategy = errors.New("prepayment strategy should be reduce_term or reduce_payment")
+	ErrNoPrepayments             = errors.New("add at least one prepayment")
+)
+
+// CalculateEarlyRepayment recomputes the payment schedule and aggregates taking prepayments into account
+// and compares the overpayment with the baseline calculation.
+func CalculateEarlyRepayment(request models.EarlyRepaymentRequest) (models.EarlyRepaymentResult, error) {
+	baseline, err := CalculateMortgageAggregates(request.LoanRequest)
+	if err != nil {
+		return models.EarlyRepaymentResult{}, err
+	}
+
+	if err = validatePrepayments(request.Prepayments, request.Months); err != nil {
+		return models.EarlyRepaymentResult{}, err
+	}
+
+	program, loanSum, loanMonths, err := prepareLoan(request.LoanRequest)
+	if err != nil {
+		return models.EarlyRepaymentResult{}, err
+	}
+
+	// The baseline has already checked the issue date.
+	issue, _ := issueDate(request.IssueDate)
+	rates := newRateSchedule(program, issue, request.PaymentDay)
+	schedule, err := repaymentSchedule(loanSum, rates, int(loanMonths.IntPart()), request.PaymentType, request.Prepayments)
+	if err != nil {
+		return models.EarlyRepaymentResult{}, err
+	}
+
+	aggregate := scheduleAggregates(schedule, loanSum, rates)
+
+	return models.EarlyRepaymentResult{
+		Aggregates:    aggregate,
+		Baseline:      baseline,
+		Params:        request.LoanParams,
+		Program:       request.Program,
+		PaymentType:   request.PaymentType,
+		Prepayments:   request.Prepayments,
+		Months:        len(schedule),
+		InterestSaved: models.NewMoney(baseline.Overpayment.Sub(aggregate.Overpayment.Decimal)),
+		Schedule:      schedule,
+	}, nil
+}
+
+// scheduleAggregates summarizes the schedule of the loan sum with the rate of the first payment
+// and the payment periods of the stepped and floating rates.
+func scheduleAggregates(schedule []models.SchedulePayment, loanSum decimal.Decimal, rates rateSchedule) models.Aggregates {
+	aggregate := summarizeSchedule(schedule)
+	aggregate.LoanSum = models.NewMoney(loanSum)
+	if len(schedule) > 0 {
+		aggregate.Rate = schedule[0].Rate
+	}
+	if !rates.fixed() {
+		aggregate.Periods = paymentPeriods(schedule)
+	}
+	return aggregate
+}
+
+// summarizeSchedule computes the payments, overpayment and last payment date of a schedule.
+func summarizeSchedule(schedule []models.SchedulePayment) models.Aggregates {
+	if len(schedule) == 0 {
+		return models.Aggregates{}
+	}
+
+	interest := decimal.Zero
+	maxPayment := decimal.Zero
+	for _, row := range schedule {
+		interest = interest.Add(row.Interest.Decimal)
+		maxPayment = decimal.Max(maxPayment, row.Payment.Decimal)
+	}
+
+	first, last := schedule[0], schedule[len(schedule)-1]
+	return models.Aggregates{
+		LastPaymentDate: last.Date,
+		MonthlyPayment:  first.Payment,
+		FirstPayment:    first.Payment,
+		LastPayment:     last.Payment,
+		MaxPayment:      models.NewMoney(maxPayment),
+		Overpayment:     models.NewMoney(interest),
+	}
+}
+
+// validatePrepayments checks that every prepayment fits the loan term and has a known strategy.
+func validatePrepayments(prepayments []models.Prepayment, months int) error {
+	if len(prepayments) == 0 {
+		return ErrNoPrepayments
+	}
+
+	for _, prepayment := range prepayments {
+		if prepayment.Month < 1 || prepayment.Month >= months {
+			return ErrPrepaymentMonth
+		}
+		if prepayment.Amount <= 0 {
+			return ErrPrepaymentAmount
+		}
+		if prepayment.Every < 0 {
+			return ErrPrepaymentPeriod
+		}
+		switch prepayment.Strategy {
+		case models.StrategyReduceTerm, models.StrategyReducePayment:
+		default:
+			return ErrUnknownPrepaymentStrategy
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+func newEarlyRepaymentRequest(paymentType string, prepayments ...models.Prepayment) models.EarlyRepaymentRequest {
+	return models.EarlyRepaymentRequest{
+		LoanRequest: models.LoanRequest{
+			LoanParams: models.LoanParams{
+				ObjectCost:     5000000,
+				InitialPayment: 1000000,
+				Months:         240,
+			},
+			Program:     models.Program{Salary: true},
+			PaymentType: paymentType,
+		},
+		Prepayments: prepayments,
+	}
+}
+
+func assertScheduleRepaid(t *testing.T, schedule []models.SchedulePayment, loanSum int64) {
+	t.Helper()
+
+	repaid := decimal.Zero
+	for _, row := range schedule {
+		repaid = repaid.Add(row.Principal.Decimal).Add(row.Prepayment.Decimal)
+	}
+	assert.True(t, repaid.Equal(decimal.NewFromInt(loanSum)), "repaid %s instead of %d", repaid, loanSum)
+	assert.True(t, schedule[len(schedule)-1].Balance.IsZero())
+}
+
+func TestCalculateEarlyRepaymentReduceTerm(t *testing.T) {
+	request := newEarlyRepaymentRequest("", models.Prepayment{Month: 36, Amount: 500000, Strategy: models.StrategyReduceTerm})
+
+	result, err := CalculateEarlyRepayment(request)
+	assert.NoError(t, err)
+	assert.Less(t, result.Months, 240)
+	assert.Equal(t, result.Months, len(result.Schedule))
+	assert.True(t, result.InterestSaved.IsPositive())
+	assert.True(t, result.Baseline.Overpayment.Sub(result.Aggregates.Overpayment.Decimal).Equal(result.InterestSaved.Decimal))
+	assert.True(t, result.Schedule[35].Prepayment.Equal(decimal.NewFromInt(500000)))
+	// The monthly payment is kept, only the last one is smaller.
+	assert.True(t, result.Schedule[36].Payment.Equal(result.Schedule[0].Payment.Decimal))
+	assert.Equal(t, result.Schedule[len(result.Schedule)-1].Date, result.Aggregates.LastPaymentDate)
+	assertScheduleRepaid(t, result.Schedule, 4000000)
+}
+
+func TestCalculateEarlyRepaymentNoSaving(t *testing.T) {
+	// One ruble before the last payment changes its interest by less than a kopeck.
+	request := newEarlyRepaymentRequest("", models.Prepayment{Month: 239, Amount: 1, Strategy: models.StrategyReduceTerm})
+
+	result, err := CalculateEarlyRepayment(request)
+	assert.NoError(t, err)
+	assert.True(t, result.InterestSaved.IsZero(), "saved %s", result.InterestSaved)
+	assert.Equal(t, result.Baseline.Overpayment, result.Aggregates.Overpayment)
+
+	aggregates, err := CalculateMortgageAggregates(request.LoanRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, aggregates, result.Baseline, "the baseline is the calculation without prepayments")
+}
+
+func TestCalculateEarlyRepaymentReducePayment(t *testing.T) {
+	request := newEarlyRepaymentRequest("", models.Prepayment{Month: 36, Amount: 500000, Strategy: models.StrategyReducePayment})
+
+	result, err := CalculateEarlyRepayment(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 240, result.Months)
+	assert.True(t, result.InterestSaved.IsPositive())
+	assert.True(t, result.Schedule[36].Payment.LessThan(result.Schedule[35].Payment.Decimal))
+	assertScheduleRepaid(t, result.Schedule, 4000000)
+}
+
+func TestCalculateEarlyRepaymentRecurring(t *testing.T) {
+	oneOff := newEarlyRepaymentRequest("", models.Prepayment{Month: 12, Amount: 100000, Strategy: models.StrategyReduceTerm})
+	recurring := newEarlyRepaymentRequest("", models.Prepayment{Month: 12, Amount: 100000, Strategy: models.StrategyReduceTerm, Every: 12})
+
+	oneOffResult, err := CalculateEarlyRepayment(oneOff)
+	assert.NoError(t, err)
+	recurringResult, err := CalculateEarlyRepayment(recurring)
+	assert.NoError(t, err)
+
+	assert.True(t, recurringResult.Schedule[23].Prepayment.Equal(decimal.NewFromInt(100000)))
+	assert.True(t, recurringResult.Schedule[24].Prepayment.IsZero())
+	assert.Less(t, recurringResult.Months, oneOffResult.Months)
+	assert.True(t, recurringResult.InterestSaved.GreaterThan(oneOffResult.InterestSaved.Decimal))
+	assertScheduleRepaid(t, recurringResult.Schedule, 4000000)
+}
+
+func TestCalculateEarlyRepaymentFullRepayment(t *testing.T) {
+	request := newEarlyRepaymentRequest("", models.Prepayment{Month: 12, Amount: 10000000, Strategy: models.StrategyReducePayment})
+
+	result, err := CalculateEarlyRepayment(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, result.Months)
+	assert.True(t, result.Schedule[11].Prepayment.LessThan(decimal.NewFromInt(4000000)))
+	assertScheduleRepaid(t, result.Schedule, 4000000)
+}
+
+func TestCalculateEarlyRepaymentDifferentiated(t *testing.T) {
+	request := newEarlyRepaymentRequest(models.PaymentTypeDifferentiated,
+		models.Prepayment{Month: 60, Amount: 1000000, Strategy: models.StrategyReduceTerm})
+
+	result, err := CalculateEarlyRepayment(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 180, result.Months)
+	assert.True(t, result.Schedule[60].Principal.Equal(result.Schedule[0].Principal.Decimal))
+	assert.True(t, result.InterestSaved.IsPositive())
+	assertScheduleRepaid(t, result.Schedule, 4000000)
+}
+
+func TestCalculateEarlyRepaymentInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   models.EarlyRepaymentRequest
+		expectErr error
+	}{
+		{
+			name:      "No prepayments",
+			request:   newEarlyRepaymentRequest(""),
+			expectErr: ErrNoPrepayments,
+		},
+		{
+			name:      "Month outside the term",
+			request:   newEarlyRepaymentRequest("", models.Prepayment{Month: 240, Amount: 1000, Strategy: models.StrategyReduceTerm}),
+			expectErr: ErrPrepaymentMonth,
+		},
+		{
+			name:      "Zero amount",
+			request:   newEarlyRepaymentRequest("", models.Prepayment{Month: 12, Strategy: models.StrategyReduceTerm}),
+			expectErr: ErrPrepaymentAmount,
+		},
+		{
+			name:      "Negative period",
+			request:   newEarlyRepaymentRequest("", models.Prepayment{Month: 12, Amount: 1000, Strategy: models.StrategyReduceTerm, Every: -1}),
+			expectErr: ErrPrepaymentPeriod,
+		},
+		{
+			name:      "Unknown strategy",
+			request:   newEarlyRepaymentRequest("", models.Prepayment{Month: 12, Amount: 1000, Strategy: "skip"}),
+			expectErr: ErrUnknownPrepaymentStrategy,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := CalculateEarlyRepayment(tc.request)
+			assert.ErrorIs(t, err, tc.expectErr)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+// Identifiers of the programs selected by the boolean flags of models.Program.
+const (
+	SalaryProgramID   = "salary"
+	MilitaryProgramID = "military"
+	BaseProgramID     = "base"
+)
+
+// Errors for program configuration.
+var (
+	ErrInvalidProgram = errors.New("invalid loan program")
+	ErrNoPrograms     = errors.New("no loan programs configured")
+)
+
+var (
+	programsMu sync.RWMutex
+	programs   = defaultPrograms()
+)
+
+// defaultPrograms returns the programs used when the configuration does not define any.
+func defaultPrograms() []models.LoanProgram {
+	newProgram := func(id, name string, rate int64) models.LoanProgram {
+		return models.LoanProgram{
+			ID:                id,
+			Name:              name,
+			Rate:              decimal.NewFromInt(rate),
+			MinInitialPercent: decimal.NewFromInt(20),
+			MinMonths:         1,
+		}
+	}
+
+	return []models.LoanProgram{
+		newProgram(SalaryProgramID, "Corporate program", 8),
+		newProgram(MilitaryProgramID, "Military mortgage", 9),
+		newProgram(BaseProgramID, "Base program", 10),
+	}
+}
+
+// SetPrograms replaces the loan programs available for calculations and drops the calculated aggregates.
+func SetPrograms(list []models.LoanProgram) error {
+	if len(list) == 0 {
+		return ErrNoPrograms
+	}
+
+	seen := make(map[string]struct{}, len(list))
+	for _, program := range list {
+		if err := validateProgram(program); err != nil {
+			return err
+		}
+		if _, ok := seen[program.ID]; ok {
+			return fmt.Errorf("%w: duplicate id %q", ErrInvalidProgram, program.ID)
+		}
+		seen[program.ID] = struct{}{}
+	}
+
+	programsMu.Lock()
+	programs = append([]models.LoanProgram(nil), list...)
+	programsMu.Unlock()
+
+	aggregateCache.Clear()
+	return nil
+}
+
+// Programs returns a copy of the configured loan programs.
+func Programs() []models.LoanProgram {
+	programsMu.RLock()
+	defer programsMu.RUnlock()
+
+	return append([]models.LoanProgram(nil), programs...)
+}
+
+// ProgramID returns the identifier of the program selected in the request, or an empty string if none.
+func ProgramID(program models.Program) string {
+	switch {
+	case program.ID != "":
+		return program.ID
+	case program.Salary:
+		return SalaryProgramID
+	case program.Military:
+		return MilitaryProgramID
+	case program.Base:
+		return BaseProgramID
+	}
+	return ""
+}
+
+// findProgram looks up a configured program by its identifier.
+func findProgram(id string) (models.LoanProgram, bool) {
+	programsMu.RLock()
+	defer programsMu.RUnlock()
+
+	for _, program := range programs {
+		if program.ID == id {
+			return program, true
+		}
+	}
+	return models.LoanProgram{}, false
+}
+
+// validateProgram checks that the program conditions are consistent.
+func validateProgram(program models.LoanProgram) error {
+	switch {
+	case program.ID == "":
+		return fmt.Errorf("%w: empty id", ErrInvalidProgram)
+	case program.Rate.IsNegative():
+		return fmt.Errorf("%w: %q has a negative rate", ErrInvalidProgram, program.ID)
+	case program.MinInitialPercent.IsNegative() || program.MinInitialPercent.GreaterThan(decimal.NewFromInt(100)):
+		return fmt.Errorf("%w: %q minimum initial payment should be between 0 and 100 percent", ErrInvalidProgram, program.ID)
+	case program.MinMonths < 0 || program.MinLoanSum < 0:
+		return fmt.Errorf("%w: %q has negative limits", ErrInvalidProgram, program.ID)
+	case program.MaxMonths > 0 && program.MaxMonths < program.MinMonths:
+		return fmt.Errorf("%w: %q maximum term is less than minimum", ErrInvalidProgram, program.ID)
+	case program.MaxLoanSum > 0 && program.MaxLoanSum < program.MinLoanSum:
+		return fmt.Errorf("%w: %q maximum loan sum is less than minimum", ErrInvalidProgram, program.ID)
+	}
+
+	for i, period := range program.RatePeriods {
+		switch {
+		case period.Months <= 0:
+			return fmt.Errorf("%w: %q rate period %d should have a positive number of months", ErrInvalidProgram, program.ID, i+1)
+		case period.Rate.IsNegative():
+			return fmt.Errorf("%w: %q rate period %d has a negative rate", ErrInvalidProgram, program.ID, i+1)
+		}
+	}
+	return nil
+}
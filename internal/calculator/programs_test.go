@@ -0,0 +1,130 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+func TestSetPrograms(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, SetPrograms(defaultPrograms()))
+	})
+
+	family := models.LoanProgram{
+		ID:                "family",
+		Name:              "Family mortgage",
+		Rate:              decimal.RequireFromString("5.95"),
+		MinInitialPercent: decimal.NewFromInt(15),
+		MinMonths:         12,
+		MaxMonths:         360,
+		MinLoanSum:        500000,
+		MaxLoanSum:        6000000,
+	}
+	assert.NoError(t, SetPrograms(append(defaultPrograms(), family)))
+	assert.Len(t, Programs(), 4)
+
+	tests := []struct {
+		name      string
+		request   models.LoanRequest
+		expectErr error
+	}{
+		{
+			name: "Configured program by id",
+			request: models.LoanRequest{
+				LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 750000, Months: 240},
+				Program:    models.Program{ID: "family"},
+			},
+		},
+		{
+			name: "Legacy flag with matching id",
+			request: models.LoanRequest{
+				LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240},
+				Program:    models.Program{ID: SalaryProgramID, Salary: true},
+			},
+		},
+		{
+			name: "Id and another flag",
+			request: models.LoanRequest{
+				LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240},
+				Program:    models.Program{ID: "family", Base: true},
+			},
+			expectErr: ErrMultiplePrograms,
+		},
+		{
+			name: "Unknown program",
+			request: models.LoanRequest{
+				LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240},
+				Program:    models.Program{ID: "rural"},
+			},
+			expectErr: ErrUnknownProgram,
+		},
+		{
+			name: "Term above the program maximum",
+			request: models.LoanRequest{
+				LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 420},
+				Program:    models.Program{ID: "family"},
+			},
+			expectErr: ErrMonthsOutOfRange,
+		},
+		{
+			name: "Loan sum above the program maximum",
+			request: models.LoanRequest{
+				LoanParams: models.LoanParams{ObjectCost: 9000000, InitialPayment: 2000000, Months: 240},
+				Program:    models.Program{ID: "family"},
+			},
+			expectErr: ErrLoanSumOutOfRange,
+		},
+		{
+			name: "Initial payment below the program minimum",
+			request: models.LoanRequest{
+				LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 700000, Months: 240},
+				Program:    models.Program{ID: "family"},
+			},
+			expectErr: ErrInitialPaymentTooLow,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := CalculateMortgageAggregates(tc.request)
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSetProgramsInvalid(t *testing.T) {
+	valid := defaultPrograms()[0]
+
+	negativeRate := valid
+	negativeRate.Rate = decimal.NewFromInt(-1)
+
+	badPercent := valid
+	badPercent.MinInitialPercent = decimal.NewFromInt(120)
+
+	badTerm := valid
+	badTerm.MinMonths, badTerm.MaxMonths = 24, 12
+
+	badSum := valid
+	badSum.MinLoanSum, badSum.MaxLoanSum = 1000000, 100
+
+	emptyPeriod := valid
+	emptyPeriod.RatePeriods = []models.RatePeriod{{Months: 0, Rate: decimal.NewFromInt(5)}}
+
+	negativePeriodRate := valid
+	negativePeriodRate.RatePeriods = []models.RatePeriod{{Months: 12, Rate: decimal.NewFromInt(-1)}}
+
+	assert.ErrorIs(t, SetPrograms(nil), ErrNoPrograms)
+	for _, program := range []models.LoanProgram{{}, negativeRate, badPercent, badTerm, badSum, emptyPeriod, negativePeriodRate} {
+		assert.ErrorIs(t, SetPrograms([]models.LoanProgram{program}), ErrInvalidProgram)
+	}
+	assert.ErrorIs(t, SetPrograms([]models.LoanProgram{valid, valid}), ErrInvalidProgram)
+	assert.Len(t, Programs(), 3)
+}
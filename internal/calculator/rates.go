@@ -0,0 +1,100 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"sbermortgagecalculator/internal/calendar"
+	"sbermortgagecalculator/internal/keyrate"
+	"sbermortgagecalculator/internal/models"
+)
+
+// ErrNoKeyRate is returned for a floating rate program when the key rate of a payment period is unknown.
+var ErrNoKeyRate = errors.New("key rate is unknown for the payment period")
+
+var (
+	keyRatesMu sync.RWMutex
+	keyRates   *keyrate.Schedule // No floating rates by default.
+)
+
+// SetKeyRates replaces the key rate schedule of the floating rates and drops the calculated aggregates.
+func SetKeyRates(schedule *keyrate.Schedule) {
+	keyRatesMu.Lock()
+	keyRates = schedule
+	keyRatesMu.Unlock()
+
+	aggregateCache.Clear()
+}
+
+// KeyRates returns the key rate schedule of the floating rates.
+func KeyRates() *keyrate.Schedule {
+	keyRatesMu.RLock()
+	defer keyRatesMu.RUnlock()
+	return keyRates
+}
+
+// rateSchedule tells the annual rates of the loan payments: the rates of the program periods,
+// then the program rate or the key rate plus the spread.
+type rateSchedule struct {
+	program    models.LoanProgram
+	keyRates   *keyrate.Schedule
+	issue      time.Time
+	paymentDay int
+}
+
+// newRateSchedule creates the rate schedule of the program for the loan issued on the issue date.
+func newRateSchedule(program models.LoanProgram, issue time.Time, paymentDay int) rateSchedule {
+	return rateSchedule{program: program, keyRates: KeyRates(), issue: issue, paymentDay: paymentDay}
+}
+
+// fixed reports whether the rate stays the same over the whole term.
+func (s rateSchedule) fixed() bool {
+	return len(s.program.RatePeriods) == 0 && s.program.KeyRateSpread == nil
+}
+
+// rate returns the annual rate in percent of the payment with the number. The floating rate follows the key rate
+// in effect at the beginning of the payment period: the issue date or the date of the previous payment.
+func (s rateSchedule) rate(number int) (decimal.Decimal, error) {
+	last := 0
+	for _, period := range s.program.RatePeriods {
+		last += period.Months
+		if number <= last {
+			return period.Rate, nil
+		}
+	}
+	if s.program.KeyRateSpread == nil {
+		return s.program.Rate, nil
+	}
+
+	start := s.issue
+	if number > 1 {
+		start = PaymentCalendar().PaymentDate(s.issue, s.paymentDay, number-1)
+	}
+	key, ok := s.keyRates.Rate(start)
+	if !ok {
+		return decimal.Zero, fmt.Errorf("%w: %s", ErrNoKeyRate, start.Format(calendar.DateLayout))
+	}
+	return decimal.Max(key.Add(*s.program.KeyRateSpread), decimal.Zero), nil
+}
+
+// paymentPeriods groups the consecutive payments of the schedule with the same rate.
+func paymentPeriods(schedule []models.SchedulePayment) []models.PaymentPeriod {
+	var periods []models.PaymentPeriod
+	for _, row := range schedule {
+		if last := len(periods) - 1; last >= 0 && periods[last].Rate.Equal(row.Rate) {
+			periods[last].ToPayment = row.Number
+			continue
+		}
+		periods = append(periods, models.PaymentPeriod{
+			FromPayment:    row.Number,
+			ToPayment:      row.Number,
+			Rate:           row.Rate,
+			MonthlyPayment: row.Payment,
+		})
+	}
+	return periods
+}
@@ -0,0 +1,154 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sbermortgagecalculator/internal/keyrate"
+	"sbermortgagecalculator/internal/models"
+)
+
+// setProgram adds the program to the default ones until the end of the test.
+func setProgram(t *testing.T, program models.LoanProgram) {
+	t.Helper()
+
+	program.MinInitialPercent = decimal.NewFromInt(20)
+	program.MinMonths = 1
+	require.NoError(t, SetPrograms(append(defaultPrograms(), program)))
+	t.Cleanup(func() {
+		assert.NoError(t, SetPrograms(defaultPrograms()))
+	})
+}
+
+func scheduleRates(schedule []models.SchedulePayment) []string {
+	rates := make([]string, 0, len(schedule))
+	for _, row := range schedule {
+		rates = append(rates, row.Rate.String())
+	}
+	return rates
+}
+
+func TestCalculatePaymentScheduleSteppedRate(t *testing.T) {
+	setProgram(t, models.LoanProgram{
+		ID:          "promo",
+		Rate:        decimal.RequireFromString("9.5"),
+		RatePeriods: []models.RatePeriod{{Months: 24, Rate: decimal.RequireFromString("5.9")}},
+	})
+
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240},
+		Program:    models.Program{ID: "promo"},
+		IssueDate:  "2025-01-15",
+	}
+	schedule, err := CalculatePaymentSchedule(request)
+	require.NoError(t, err)
+	require.Len(t, schedule, 240)
+
+	assert.Equal(t, "5.9", schedule[23].Rate.String())
+	assert.Equal(t, "28426.96", schedule[23].Payment.StringFixed(2))
+	assert.Equal(t, "3777425.36", schedule[23].Balance.StringFixed(2))
+
+	// The annuity is recalculated for the balance and the remaining 216 months at the new rate.
+	assert.Equal(t, "9.5", schedule[24].Rate.String())
+	assert.Equal(t, "36562.13", schedule[24].Payment.StringFixed(2))
+	assert.Equal(t, "36562.13", schedule[238].Payment.StringFixed(2))
+	assertScheduleRepaid(t, schedule, 4000000)
+
+	aggregate, err := CalculateMortgageAggregates(request)
+	require.NoError(t, err)
+	assert.Equal(t, "5.9", aggregate.Rate.String())
+	assert.Equal(t, "28426.96", aggregate.MonthlyPayment.StringFixed(2))
+	assert.Equal(t, schedule[239].Payment, aggregate.LastPayment)
+	assert.Equal(t, "36563.68", aggregate.MaxPayment.StringFixed(2), "the last payment absorbs the rounding")
+	assert.Equal(t, schedule[239].Date, aggregate.LastPaymentDate)
+	assert.Len(t, aggregate.Periods, 2)
+	assert.Equal(t, models.PaymentPeriod{
+		FromPayment: 25, ToPayment: 240, Rate: schedule[24].Rate, MonthlyPayment: schedule[24].Payment,
+	}, aggregate.Periods[1])
+
+	interest := decimal.Zero
+	for _, row := range schedule {
+		interest = interest.Add(row.Interest.Decimal)
+	}
+	assert.True(t, aggregate.Overpayment.Equal(interest), "overpayment %s, interest %s", aggregate.Overpayment, interest)
+
+	v1 := aggregate.V1(models.RoundingHalfUp)
+	assert.Equal(t, []models.PaymentPeriodV1{
+		{FromPayment: 1, ToPayment: 24, Rate: 6, MonthlyPayment: 28427},
+		{FromPayment: 25, ToPayment: 240, Rate: 10, MonthlyPayment: 36562},
+	}, v1.Periods)
+}
+
+func TestCalculatePaymentScheduleFloatingRate(t *testing.T) {
+	spread := decimal.NewFromInt(2)
+	setProgram(t, models.LoanProgram{
+		ID:            "floating",
+		RatePeriods:   []models.RatePeriod{{Months: 1, Rate: decimal.NewFromInt(5)}},
+		KeyRateSpread: &spread,
+	})
+
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 4},
+		Program:    models.Program{ID: "floating"},
+		IssueDate:  "2025-01-15",
+	}
+	_, err := CalculatePaymentSchedule(request)
+	assert.ErrorIs(t, err, ErrNoKeyRate)
+	assert.Equal(t, "no_key_rate", ErrorCode(err))
+
+	schedule, err := keyrate.New([]keyrate.Change{
+		{Date: "2025-01-01", Rate: decimal.NewFromInt(16)},
+		{Date: "2025-03-20", Rate: decimal.NewFromInt(12)},
+	})
+	require.NoError(t, err)
+	SetKeyRates(schedule)
+	defer SetKeyRates(nil)
+
+	// The rate follows the key rate at the beginning of the payment period: the previous payment date.
+	rows, err := CalculatePaymentSchedule(request)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5", "18", "18", "14"}, scheduleRates(rows))
+	assert.Equal(t, "2025-03-17", rows[1].Date)
+	assert.Equal(t, "2025-04-15", rows[2].Date)
+	assertScheduleRepaid(t, rows, 4000000)
+
+	aggregate, err := CalculateMortgageAggregates(request)
+	require.NoError(t, err)
+	assert.Equal(t, "5", aggregate.Rate.String())
+	assert.Equal(t, []models.PaymentPeriod{
+		{FromPayment: 1, ToPayment: 1, Rate: rows[0].Rate, MonthlyPayment: rows[0].Payment},
+		{FromPayment: 2, ToPayment: 3, Rate: rows[1].Rate, MonthlyPayment: rows[1].Payment},
+		{FromPayment: 4, ToPayment: 4, Rate: rows[3].Rate, MonthlyPayment: rows[3].Payment},
+	}, aggregate.Periods)
+
+	request.IssueDate = "2024-11-15"
+	_, err = CalculateMortgageAggregates(request)
+	assert.ErrorIs(t, err, ErrNoKeyRate, "the key rate on the date of the first payment is unknown")
+}
+
+func TestCalculateEarlyRepaymentSteppedRate(t *testing.T) {
+	setProgram(t, models.LoanProgram{
+		ID:          "promo",
+		Rate:        decimal.RequireFromString("9.5"),
+		RatePeriods: []models.RatePeriod{{Months: 24, Rate: decimal.RequireFromString("5.9")}},
+	})
+
+	request := newEarlyRepaymentRequest("", models.Prepayment{Month: 12, Amount: 500000, Strategy: models.StrategyReducePayment})
+	request.Program = models.Program{ID: "promo"}
+
+	result, err := CalculateEarlyRepayment(request)
+	require.NoError(t, err)
+	assertScheduleRepaid(t, result.Schedule, 4000000)
+	assert.True(t, result.InterestSaved.IsPositive())
+
+	// The periods follow the rate, the payment after the reset is recalculated for the prepaid balance.
+	periods, baseline := result.Aggregates.Periods, result.Baseline.Periods
+	assert.Len(t, periods, 2)
+	assert.Equal(t, 25, periods[1].FromPayment)
+	assert.Equal(t, "9.5", periods[1].Rate.String())
+	assert.True(t, periods[1].MonthlyPayment.LessThan(baseline[1].MonthlyPayment.Decimal),
+		"payment %s, baseline %s", periods[1].MonthlyPayment, baseline[1].MonthlyPayment)
+}
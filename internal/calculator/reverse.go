@@ -0,0 +1,313 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+
+	"github.com/shopspring/decimal"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+// maxReverseMonths limits the solved loan term for programs without a maximum term.
+const maxReverseMonths = 600
+
+// Errors for reverse calculation.
+var (
+	ErrTargetPaymentZeroOrNegative = errors.New("target monthly payment must be greater than zero")
+	ErrReverseTarget               = errors.New("specify either the loan term or the loan sum")
+	ErrPaymentBelowInterest        = errors.New("target monthly payment does not cover the loan interest")
+	ErrProgramNotFinanced          = errors.New("the program requires the whole object cost as the initial payment")
+)
+
+// CalculateReverse solves the loan for the target monthly payment. With the term given it finds the maximum
+// loan sum, with the loan sum given it finds the shortest term. The object cost is implied by the program
+// minimum initial payment, and the solved loan passes the same validation as CalculateMortgageAggregates.
+// With the stepped or floating rate the target is the maximum payment over the rate periods.
+func CalculateReverse(request models.ReverseRequest) (models.ReverseResult, error) {
+	if request.MonthlyPayment <= 0 {
+		return models.ReverseResult{}, ErrTargetPaymentZeroOrNegative
+	}
+
+	program, err := selectProgram(request.Program)
+	if err != nil {
+		return models.ReverseResult{}, err
+	}
+
+	issue, err := issueDate(request.IssueDate)
+	if err != nil {
+		return models.ReverseResult{}, err
+	}
+
+	// The payments at the first rate bound the solution, the other rate periods are checked against the schedule.
+	rates := newRateSchedule(program, issue, 0)
+	rate, err := rates.rate(1)
+	if err != nil {
+		return models.ReverseResult{}, err
+	}
+
+	solver := reverseSolver{
+		payment:     decimal.NewFromInt(int64(request.MonthlyPayment)),
+		monthlyRate: calculateMonthlyRate(rate),
+		rates:       rates,
+		paymentType: request.PaymentType,
+		rounding:    Rounding(),
+	}
+
+	loanSum, months := request.LoanSum, request.Months
+	switch {
+	case loanSum == 0:
+		loanSum, err = solver.loanSum(months, program)
+	case months == 0:
+		months, err = solver.months(loanSum, program)
+	default:
+		err = ErrReverseTarget
+	}
+	if err != nil {
+		return models.ReverseResult{}, err
+	}
+
+	params, err := impliedParams(loanSum, months, program)
+	if err != nil {
+		return models.ReverseResult{}, err
+	}
+
+	aggregates, err := CalculateMortgageAggregates(models.LoanRequest{
+		LoanParams:  params,
+		Program:     request.Program,
+		PaymentType: request.PaymentType,
+		IssueDate:   request.IssueDate,
+	})
+	if err != nil {
+		return models.ReverseResult{}, err
+	}
+
+	return models.ReverseResult{
+		Aggregates:    aggregates,
+		Params:        params,
+		Program:       request.Program,
+		PaymentType:   request.PaymentType,
+		TargetPayment: request.MonthlyPayment,
+	}, nil
+}
+
+// reverseSolver finds the loan parameters matching the target monthly payment.
+type reverseSolver struct {
+	payment     decimal.Decimal // Target monthly payment.
+	monthlyRate decimal.Decimal // Monthly interest rate of the first payment in decimal form.
+	rates       rateSchedule    // Annual rates of the payments.
+	paymentType string          // Payment scheme.
+	rounding    models.RoundingMode
+}
+
+// differentiated reports whether the first differentiated payment is matched.
+func (s reverseSolver) differentiated() bool {
+	return s.paymentType == models.PaymentTypeDifferentiated
+}
+
+// loanSum finds the maximum loan sum in whole rubles whose payment does not exceed the target.
+func (s reverseSolver) loanSum(months int, program models.LoanProgram) (int, error) {
+	if months <= 0 {
+		return 0, ErrMonthsShouldBePositive
+	}
+
+	one := decimal.NewFromInt(1)
+	term := decimal.NewFromInt(int64(months))
+
+	var loanSum decimal.Decimal
+	switch {
+	case s.differentiated():
+		// First payment: P = S / T + S * G, so S = P * T / (1 + G * T).
+		loanSum = s.payment.Mul(term).Div(one.Add(s.monthlyRate.Mul(term)))
+	case s.monthlyRate.IsZero():
+		loanSum = s.payment.Mul(term)
+	default:
+		// Inverted annuity formula: S = P * ((1 + G)^T - 1) / (G * (1 + G)^T).
+		compoundRate := one.Add(s.monthlyRate).Pow(term)
+		loanSum = s.payment.Mul(compoundRate.Sub(one)).Div(s.monthlyRate.Mul(compoundRate))
+	}
+	loanSum, err := s.adjustLoanSum(loanSum.Floor(), term)
+	if err != nil {
+		return 0, err
+	}
+	if !s.rates.fixed() {
+		if loanSum, err = s.scheduleLoanSum(loanSum, months); err != nil {
+			return 0, err
+		}
+	}
+
+	if program.MaxLoanSum > 0 && loanSum.GreaterThan(decimal.NewFromInt(int64(program.MaxLoanSum))) {
+		return program.MaxLoanSum, nil
+	}
+	return int(loanSum.IntPart()), nil
+}
+
+// adjustLoanSum corrects the estimated loan sum by whole rubles, as the rounded payment may differ from the exact one.
+func (s reverseSolver) adjustLoanSum(loanSum, term decimal.Decimal) (decimal.Decimal, error) {
+	one := decimal.NewFromInt(1)
+	for loanSum.IsPositive() {
+		payment, err := s.paymentFor(loanSum, term)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		if payment.LessThanOrEqual(s.payment) {
+			break
+		}
+		loanSum = loanSum.Sub(one)
+	}
+
+	for {
+		payment, err := s.paymentFor(loanSum.Add(one), term)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		if payment.GreaterThan(s.payment) {
+			return loanSum, nil
+		}
+		loanSum = loanSum.Add(one)
+	}
+}
+
+// scheduleLoanSum finds the maximum loan sum in whole rubles up to the limit whose schedule payments
+// do not exceed the target. The maximum payment grows with the loan sum, so the sum is searched by bisection.
+func (s reverseSolver) scheduleLoanSum(limit decimal.Decimal, months int) (decimal.Decimal, error) {
+	low, high := int64(0), limit.IntPart()
+	for low < high {
+		middle := (low + high + 1) / 2
+		payment, err := s.maxPayment(decimal.NewFromInt(middle), months)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		if payment.LessThanOrEqual(s.payment) {
+			low = middle
+		} else {
+			high = middle - 1
+		}
+	}
+	return decimal.NewFromInt(low), nil
+}
+
+// months finds the shortest term whose payment does not exceed the target, but not shorter than the program minimum.
+func (s reverseSolver) months(loanSum int, program models.LoanProgram) (int, error) {
+	if loanSum <= 0 {
+		return 0, ErrLoanSumZeroOrNegative
+	}
+
+	balance := float64(loanSum)
+	payment := s.payment.InexactFloat64()
+	rate := s.monthlyRate.InexactFloat64()
+	if balance*rate >= payment {
+		return 0, ErrPaymentBelowInterest
+	}
+
+	estimate := balance / payment
+	switch {
+	case s.differentiated():
+		// First payment: P = S / T + S * G, so T = S / (P - S * G).
+		estimate = balance / (payment - balance*rate)
+	case rate > 0:
+		// Annuity term: T = -ln(1 - S * G / P) / ln(1 + G).
+		estimate = -math.Log(1-balance*rate/payment) / math.Log(1+rate)
+	}
+
+	maxMonths := maxReverseMonths
+	if program.MaxMonths > 0 {
+		maxMonths = program.MaxMonths
+	}
+	if estimate-termEpsilon > float64(maxMonths) {
+		return 0, ErrMonthsOutOfRange
+	}
+
+	months := max(int(math.Ceil(estimate-termEpsilon)), program.MinMonths, 1)
+
+	// The rounded payment may exceed the target by a kopeck.
+	for ; months <= maxMonths; months++ {
+		monthly, err := s.paymentFor(decimal.NewFromInt(int64(loanSum)), decimal.NewFromInt(int64(months)))
+		if err != nil {
+			return 0, err
+		}
+		if monthly.LessThanOrEqual(s.payment) {
+			break
+		}
+	}
+	if months > maxMonths {
+		return 0, ErrMonthsOutOfRange
+	}
+
+	if s.rates.fixed() {
+		return months, nil
+	}
+	return s.scheduleMonths(decimal.NewFromInt(int64(loanSum)), months, maxMonths)
+}
+
+// scheduleMonths finds the shortest term from the minimum up to the maximum whose schedule payments do not exceed
+// the target. The maximum payment falls as the term grows, so the term is searched by bisection.
+func (s reverseSolver) scheduleMonths(loanSum decimal.Decimal, minMonths, maxMonths int) (int, error) {
+	payment, err := s.maxPayment(loanSum, maxMonths)
+	if err != nil {
+		return 0, err
+	}
+	if payment.GreaterThan(s.payment) {
+		return 0, ErrMonthsOutOfRange
+	}
+
+	low, high := minMonths, maxMonths
+	for low < high {
+		middle := (low + high) / 2
+		if payment, err = s.maxPayment(loanSum, middle); err != nil {
+			return 0, err
+		}
+		if payment.LessThanOrEqual(s.payment) {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+	return low, nil
+}
+
+// maxPayment computes the maximum payment of the loan schedule over the rate periods.
+func (s reverseSolver) maxPayment(loanSum decimal.Decimal, months int) (decimal.Decimal, error) {
+	if !loanSum.IsPositive() {
+		return decimal.Zero, nil
+	}
+	schedule, err := repaymentSchedule(loanSum, s.rates, months, s.paymentType, nil)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return summarizeSchedule(schedule).MaxPayment.Decimal, nil
+}
+
+// paymentFor computes the rounded monthly payment, the first one for the differentiated scheme.
+func (s reverseSolver) paymentFor(loanSum, months decimal.Decimal) (decimal.Decimal, error) {
+	if s.differentiated() {
+		return s.rounding.Round(loanSum.Div(months).Add(loanSum.Mul(s.monthlyRate))), nil
+	}
+
+	payment, err := calculateMonthlyPayment(loanSum, s.monthlyRate, months)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return s.rounding.Round(payment), nil
+}
+
+// impliedParams computes the lowest object cost for which the loan sum leaves the program minimum initial payment.
+func impliedParams(loanSum, months int, program models.LoanProgram) (models.LoanParams, error) {
+	hundred := decimal.NewFromInt(100)
+	financedPercent := hundred.Sub(program.MinInitialPercent)
+	if !financedPercent.IsPositive() {
+		return models.LoanParams{}, ErrProgramNotFinanced
+	}
+	if loanSum <= 0 {
+		return models.LoanParams{}, ErrLoanSumZeroOrNegative
+	}
+
+	// Object cost: C = S * 100 / (100 - minimum initial percent), rounded up to keep the minimum.
+	objectCost := int(decimal.NewFromInt(int64(loanSum)).Mul(hundred).Div(financedPercent).Ceil().IntPart())
+
+	return models.LoanParams{
+		ObjectCost:     objectCost,
+		InitialPayment: objectCost - loanSum,
+		Months:         months,
+	}, nil
+}
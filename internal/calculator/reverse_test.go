@@ -0,0 +1,211 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+func TestCalculateReverseLoanSum(t *testing.T) {
+	tests := []struct {
+		name           string
+		paymentType    string
+		expectedParams models.LoanParams
+	}{
+		{
+			name:           "Annuity",
+			expectedParams: models.LoanParams{ObjectCost: 8966573, InitialPayment: 1793315, Months: 240},
+		},
+		{
+			name:           "Differentiated",
+			paymentType:    models.PaymentTypeDifferentiated,
+			expectedParams: models.LoanParams{ObjectCost: 6923077, InitialPayment: 1384616, Months: 240},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			request := models.ReverseRequest{
+				MonthlyPayment: 60000,
+				Months:         240,
+				Program:        models.Program{Salary: true},
+				PaymentType:    tc.paymentType,
+			}
+
+			result, err := CalculateReverse(request)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedParams, result.Params)
+			assert.Equal(t, 60000, result.TargetPayment)
+			assert.True(t, result.Aggregates.MonthlyPayment.LessThanOrEqual(decimal.NewFromInt(60000)))
+
+			// One more ruble of the loan exceeds the target payment.
+			params := result.Params
+			params.ObjectCost += 2
+			params.InitialPayment++
+			bigger, err := CalculateMortgageAggregates(models.LoanRequest{LoanParams: params, Program: request.Program, PaymentType: tc.paymentType})
+			assert.NoError(t, err)
+			assert.True(t, bigger.MonthlyPayment.GreaterThan(decimal.NewFromInt(60000)))
+		})
+	}
+}
+
+func TestCalculateReverseMonths(t *testing.T) {
+	tests := []struct {
+		name            string
+		payment         int
+		paymentType     string
+		expectedMonths  int
+		expectedPayment string
+	}{
+		{name: "Annuity", payment: 60000, expectedMonths: 89, expectedPayment: "59733.35"},
+		{name: "Exact payment", payment: 33458, expectedMonths: 240, expectedPayment: "33457.60"},
+		{name: "Differentiated", payment: 60000, paymentType: models.PaymentTypeDifferentiated, expectedMonths: 120, expectedPayment: "60000.00"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := CalculateReverse(models.ReverseRequest{
+				MonthlyPayment: tc.payment,
+				LoanSum:        4000000,
+				Program:        models.Program{Salary: true},
+				PaymentType:    tc.paymentType,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: tc.expectedMonths}, result.Params)
+			assert.Equal(t, tc.expectedPayment, result.Aggregates.MonthlyPayment.StringFixed(2))
+		})
+	}
+}
+
+func TestCalculateReverseProgramLimits(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, SetPrograms(defaultPrograms()))
+	})
+
+	family := models.LoanProgram{
+		ID:                "family",
+		Rate:              decimal.RequireFromString("5.95"),
+		MinInitialPercent: decimal.NewFromInt(15),
+		MinMonths:         12,
+		MaxMonths:         360,
+		MaxLoanSum:        6000000,
+	}
+	assert.NoError(t, SetPrograms([]models.LoanProgram{family}))
+
+	result, err := CalculateReverse(models.ReverseRequest{MonthlyPayment: 100000, Months: 360, Program: models.Program{ID: "family"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "6000000.00", result.Aggregates.LoanSum.StringFixed(2))
+	assert.Equal(t, models.LoanParams{ObjectCost: 7058824, InitialPayment: 1058824, Months: 360}, result.Params)
+
+	result, err = CalculateReverse(models.ReverseRequest{MonthlyPayment: 1000000, LoanSum: 1000000, Program: models.Program{ID: "family"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, result.Params.Months)
+
+	_, err = CalculateReverse(models.ReverseRequest{MonthlyPayment: 25000, LoanSum: 5000000, Program: models.Program{ID: "family"}})
+	assert.ErrorIs(t, err, ErrMonthsOutOfRange)
+
+	_, err = CalculateReverse(models.ReverseRequest{MonthlyPayment: 30000, Months: 6, Program: models.Program{ID: "family"}})
+	assert.ErrorIs(t, err, ErrMonthsOutOfRange)
+}
+
+func TestCalculateReverseSteppedRate(t *testing.T) {
+	setProgram(t, models.LoanProgram{
+		ID:          "promo",
+		Rate:        decimal.RequireFromString("9.5"),
+		RatePeriods: []models.RatePeriod{{Months: 24, Rate: decimal.RequireFromString("5.9")}},
+	})
+	program := models.Program{ID: "promo"}
+
+	// The payments after the promo period are higher, so they limit the loan sum.
+	result, err := CalculateReverse(models.ReverseRequest{MonthlyPayment: 36562, Months: 240, Program: program, IssueDate: "2025-01-15"})
+	require.NoError(t, err)
+	assert.True(t, result.Aggregates.MaxPayment.LessThanOrEqual(decimal.NewFromInt(36562)), "max payment %s", result.Aggregates.MaxPayment)
+	assert.Len(t, result.Aggregates.Periods, 2)
+
+	larger := models.LoanRequest{LoanParams: result.Params, Program: program, IssueDate: "2025-01-15"}
+	larger.ObjectCost++
+	aggregate, err := CalculateMortgageAggregates(larger)
+	require.NoError(t, err)
+	assert.True(t, aggregate.MaxPayment.GreaterThan(decimal.NewFromInt(36562)), "max payment %s", aggregate.MaxPayment)
+
+	result, err = CalculateReverse(models.ReverseRequest{MonthlyPayment: 36564, LoanSum: 4000000, Program: program, IssueDate: "2025-01-15"})
+	require.NoError(t, err)
+	assert.Equal(t, 240, result.Params.Months)
+	assert.Equal(t, "36563.68", result.Aggregates.MaxPayment.StringFixed(2))
+
+	shorter := models.LoanRequest{LoanParams: result.Params, Program: program, IssueDate: "2025-01-15"}
+	shorter.Months--
+	aggregate, err = CalculateMortgageAggregates(shorter)
+	require.NoError(t, err)
+	assert.True(t, aggregate.MaxPayment.GreaterThan(decimal.NewFromInt(36564)), "max payment %s", aggregate.MaxPayment)
+
+	_, err = CalculateReverse(models.ReverseRequest{MonthlyPayment: 36564, LoanSum: 4000000, Program: program, IssueDate: "15.01.2025"})
+	assert.ErrorIs(t, err, ErrInvalidIssueDate)
+}
+
+func TestCalculateReverseProgramNotFinanced(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, SetPrograms(defaultPrograms()))
+	})
+
+	cash := models.LoanProgram{ID: "cash", Rate: decimal.NewFromInt(8), MinInitialPercent: decimal.NewFromInt(100), MinMonths: 1}
+	require.NoError(t, SetPrograms([]models.LoanProgram{cash}))
+
+	_, err := CalculateReverse(models.ReverseRequest{MonthlyPayment: 60000, Months: 120, Program: models.Program{ID: "cash"}})
+	assert.ErrorIs(t, err, ErrProgramNotFinanced)
+}
+
+func TestCalculateReverseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  models.ReverseRequest
+		expected error
+	}{
+		{
+			name:     "Zero payment",
+			request:  models.ReverseRequest{Months: 240, Program: models.Program{Salary: true}},
+			expected: ErrTargetPaymentZeroOrNegative,
+		},
+		{
+			name:     "No program",
+			request:  models.ReverseRequest{MonthlyPayment: 60000, Months: 240},
+			expected: ErrNoProgramSelected,
+		},
+		{
+			name:     "Both targets",
+			request:  models.ReverseRequest{MonthlyPayment: 60000, Months: 240, LoanSum: 4000000, Program: models.Program{Salary: true}},
+			expected: ErrReverseTarget,
+		},
+		{
+			name:     "No term",
+			request:  models.ReverseRequest{MonthlyPayment: 60000, Program: models.Program{Salary: true}},
+			expected: ErrMonthsShouldBePositive,
+		},
+		{
+			name:     "Negative loan sum",
+			request:  models.ReverseRequest{MonthlyPayment: 60000, LoanSum: -1, Program: models.Program{Salary: true}},
+			expected: ErrLoanSumZeroOrNegative,
+		},
+		{
+			name:     "Payment below interest",
+			request:  models.ReverseRequest{MonthlyPayment: 26000, LoanSum: 4000000, Program: models.Program{Salary: true}},
+			expected: ErrPaymentBelowInterest,
+		},
+		{
+			name:     "Unknown payment type",
+			request:  models.ReverseRequest{MonthlyPayment: 60000, Months: 240, Program: models.Program{Salary: true}, PaymentType: "balloon"},
+			expected: ErrUnknownPaymentType,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := CalculateReverse(tc.request)
+			assert.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
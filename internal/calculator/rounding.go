@@ -0,0 +1,38 @@
+package calculator
+
+import (
+	"errors"
+	"sync"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+// ErrUnknownRoundingMode is returned for rounding modes other than half_up, bankers and ceil_ruble.
+var ErrUnknownRoundingMode = errors.New("rounding mode should be half_up, bankers or ceil_ruble")
+
+var (
+	roundingMu   sync.RWMutex
+	roundingMode = models.RoundingHalfUp
+)
+
+// SetRoundingMode selects how money amounts are rounded and drops the calculated aggregates.
+func SetRoundingMode(mode models.RoundingMode) error {
+	if !mode.Valid() {
+		return ErrUnknownRoundingMode
+	}
+
+	roundingMu.Lock()
+	roundingMode = mode
+	roundingMu.Unlock()
+
+	aggregateCache.Clear()
+	return nil
+}
+
+// Rounding returns the rounding mode of money amounts.
+func Rounding() models.RoundingMode {
+	roundingMu.RLock()
+	defer roundingMu.RUnlock()
+
+	return roundingMode
+}
@@ -0,0 +1,197 @@
+package calculator
+
+import (
+	"math"
+
+	"github.com/shopspring/decimal"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+// termEpsilon absorbs kopeck rounding when the remaining term is recalculated.
+const termEpsilon = 1e-6
+
+// CalculatePaymentSchedule builds the month-by-month payment schedule for the loan.
+// Amounts are rounded with the rounding mode, the last payment absorbs the rounding so the balance ends at zero.
+// The annuity payment is recalculated for the remaining term whenever the rate changes.
+func CalculatePaymentSchedule(request models.LoanRequest) ([]models.SchedulePayment, error) {
+	program, loanSum, loanMonths, err := prepareLoan(request)
+	if err != nil {
+		return nil, err
+	}
+
+	issue, err := issueDate(request.IssueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := newRateSchedule(program, issue, request.PaymentDay)
+	return repaymentSchedule(loanSum, rates, int(loanMonths.IntPart()), request.PaymentType, nil)
+}
+
+// repaymentSchedule builds the schedule of the loan at the rates with the prepayments, dated by the rate schedule.
+func repaymentSchedule(loanSum decimal.Decimal, rates rateSchedule, months int, paymentType string, prepayments []models.Prepayment) ([]models.SchedulePayment, error) {
+	plan, err := newRepaymentPlan(loanSum, rates, months, paymentType)
+	if err != nil {
+		return nil, err
+	}
+	return plan.schedule(prepayments, paymentDates(rates.issue, rates.paymentDay))
+}
+
+// repaymentPlan tracks the state of the loan while its schedule is generated.
+type repaymentPlan struct {
+	balance        decimal.Decimal // Remaining loan balance.
+	rates          rateSchedule    // Annual rates of the payments.
+	rate           decimal.Decimal // Annual interest rate of the current payment in percent.
+	monthlyRate    decimal.Decimal // Monthly interest rate in decimal form.
+	installment    decimal.Decimal // Annuity payment or principal part of a differentiated payment.
+	remaining      int             // Number of payments left.
+	differentiated bool            // Whether the principal part is constant.
+	rounding       models.RoundingMode
+}
+
+// newRepaymentPlan creates the plan for the whole loan term at the rate of the first payment.
+func newRepaymentPlan(loanSum decimal.Decimal, rates rateSchedule, months int, paymentType string) (*repaymentPlan, error) {
+	plan := &repaymentPlan{
+		balance:        loanSum,
+		rates:          rates,
+		remaining:      months,
+		differentiated: paymentType == models.PaymentTypeDifferentiated,
+		rounding:       Rounding(),
+	}
+	if err := plan.setRate(1); err != nil {
+		return nil, err
+	}
+	if err := plan.resetInstallment(); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// schedule generates the payment rows dated by the number of the payment, making the prepayments due
+// after each regular payment.
+func (p *repaymentPlan) schedule(prepayments []models.Prepayment, dates func(number int) string) ([]models.SchedulePayment, error) {
+	rows := make([]models.SchedulePayment, 0, p.remaining)
+	for number := 1; p.remaining > 0 && p.balance.IsPositive(); number++ {
+		if err := p.setRate(number); err != nil {
+			return nil, err
+		}
+		row := p.pay(number, dates(number))
+		for _, prepayment := range prepayments {
+			if !prepaymentDue(prepayment, number) || !p.balance.IsPositive() {
+				continue
+			}
+			paid, err := p.prepay(decimal.NewFromInt(int64(prepayment.Amount)), prepayment.Strategy)
+			if err != nil {
+				return nil, err
+			}
+			row.Prepayment = models.NewMoney(row.Prepayment.Add(paid))
+		}
+		row.Balance = models.NewMoney(p.balance)
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// pay makes the next regular payment and returns its schedule row.
+func (p *repaymentPlan) pay(number int, date string) models.SchedulePayment {
+	interest := p.rounding.Round(p.balance.Mul(p.monthlyRate))
+	principal := p.installment
+	if !p.differentiated {
+		principal = p.installment.Sub(interest)
+	}
+	// The last payment repays the whole remaining balance.
+	if p.remaining <= 1 || principal.GreaterThan(p.balance) {
+		principal = p.balance
+	}
+	p.balance = p.balance.Sub(principal)
+	p.remaining--
+
+	return models.SchedulePayment{
+		Number:    number,
+		Date:      date,
+		Rate:      p.rate,
+		Payment:   models.NewMoney(interest.Add(principal)),
+		Interest:  models.NewMoney(interest),
+		Principal: models.NewMoney(principal),
+		Balance:   models.NewMoney(p.balance),
+	}
+}
+
+// setRate switches the plan to the rate of the payment with the number. The annuity payment is recalculated
+// for the remaining balance and term when the rate changes, the principal part of a differentiated one stays.
+func (p *repaymentPlan) setRate(number int) error {
+	rate, err := p.rates.rate(number)
+	if err != nil {
+		return err
+	}
+	if number > 1 && rate.Equal(p.rate) {
+		return nil
+	}
+
+	p.rate = rate
+	p.monthlyRate = calculateMonthlyRate(rate)
+	if number == 1 || p.differentiated {
+		return nil
+	}
+	return p.resetInstallment()
+}
+
+// prepay makes an early repayment and recalculates the plan according to the strategy.
+// It returns the amount actually paid, which never exceeds the balance.
+func (p *repaymentPlan) prepay(amount decimal.Decimal, strategy string) (decimal.Decimal, error) {
+	amount = decimal.Min(amount, p.balance)
+	p.balance = p.balance.Sub(amount)
+	if !p.balance.IsPositive() || p.remaining == 0 {
+		return amount, nil
+	}
+
+	if strategy == models.StrategyReducePayment {
+		return amount, p.resetInstallment()
+	}
+	p.resetTerm()
+	return amount, nil
+}
+
+// resetInstallment spreads the balance over the remaining payments.
+func (p *repaymentPlan) resetInstallment() error {
+	months := decimal.NewFromInt(int64(p.remaining))
+	if p.differentiated {
+		if months.IsZero() {
+			return ErrCalculationError
+		}
+		p.installment = p.rounding.Round(p.balance.Div(months))
+		return nil
+	}
+
+	payment, err := calculateMonthlyPayment(p.balance, p.monthlyRate, months)
+	if err != nil {
+		return err
+	}
+	p.installment = p.rounding.Round(payment)
+	return nil
+}
+
+// resetTerm recalculates the number of payments left keeping the installment.
+func (p *repaymentPlan) resetTerm() {
+	balance := p.balance.InexactFloat64()
+	installment := p.installment.InexactFloat64()
+	rate := p.monthlyRate.InexactFloat64()
+
+	months := balance / installment
+	if !p.differentiated && rate > 0 {
+		// Annuity term: T = -ln(1 - S * G / P) / ln(1 + G).
+		months = -math.Log(1-balance*rate/installment) / math.Log(1+rate)
+	}
+
+	p.remaining = max(int(math.Ceil(months-termEpsilon)), 1)
+}
+
+// prepaymentDue reports whether the prepayment is made together with the given payment.
+func prepaymentDue(prepayment models.Prepayment, number int) bool {
+	if number == prepayment.Month {
+		return true
+	}
+	return prepayment.Every > 0 && number > prepayment.Month && (number-prepayment.Month)%prepayment.Every == 0
+}
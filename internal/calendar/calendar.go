@@ -0,0 +1,109 @@
+// Package calendar computes the payment dates of the loans on business days.
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DateLayout is the format of the dates in the responses and the calendar file.
+const DateLayout = "2006-01-02"
+
+// ErrInvalidCalendar is returned for a production calendar with a malformed date.
+var ErrInvalidCalendar = errors.New("invalid production calendar")
+
+// Calendar tells the business days: the weekdays except the holidays and the weekend days declared working days.
+// The zero calendar has no holidays, so only the weekends are days off.
+type Calendar struct {
+	holidays map[string]struct{}
+	workdays map[string]struct{}
+}
+
+// File is the production calendar file: the holidays falling on weekdays, including the days off transferred
+// from the weekends, and the weekend days declared working days.
+type File struct {
+	Holidays []string `yaml:"holidays"` // Days off in the YYYY-MM-DD format.
+	Workdays []string `yaml:"workdays"` // Working Saturdays and Sundays in the YYYY-MM-DD format.
+}
+
+// New creates the calendar with the days off and the working weekend days in the YYYY-MM-DD format.
+func New(holidays, workdays []string) (*Calendar, error) {
+	calendar := &Calendar{}
+	var err error
+	if calendar.holidays, err = parseDays(holidays); err != nil {
+		return nil, err
+	}
+	if calendar.workdays, err = parseDays(workdays); err != nil {
+		return nil, err
+	}
+	return calendar, nil
+}
+
+// Load reads the production calendar from the YAML file.
+func Load(path string) (*Calendar, error) {
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("failed to read production calendar: %w", err)
+	}
+
+	var file File
+	if err = yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal production calendar: %w", err)
+	}
+	return New(file.Holidays, file.Workdays)
+}
+
+func parseDays(days []string) (map[string]struct{}, error) {
+	set := make(map[string]struct{}, len(days))
+	for _, day := range days {
+		date, err := time.Parse(DateLayout, day)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a YYYY-MM-DD date", ErrInvalidCalendar, day)
+		}
+		set[date.Format(DateLayout)] = struct{}{}
+	}
+	return set, nil
+}
+
+// BusinessDay reports whether the day is a working day.
+func (c *Calendar) BusinessDay(day time.Time) bool {
+	key := day.Format(DateLayout)
+	if c != nil {
+		if _, ok := c.holidays[key]; ok {
+			return false
+		}
+		if _, ok := c.workdays[key]; ok {
+			return true
+		}
+	}
+	weekday := day.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday
+}
+
+// NextBusinessDay returns the day itself if it is a working day, otherwise the first working day after it.
+func (c *Calendar) NextBusinessDay(day time.Time) time.Time {
+	for !c.BusinessDay(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// PaymentDate returns the date of the payment with the number for the loan issued on the issue date.
+// The payments are due on the payment day of the month, the issue day if the payment day is not positive,
+// or on the last day of the shorter months. A due date falling on a day off moves to the next working day.
+func (c *Calendar) PaymentDate(issue time.Time, paymentDay, number int) time.Time {
+	if paymentDay <= 0 {
+		paymentDay = issue.Day()
+	}
+
+	// The first day of the month does not overflow, unlike AddDate on January 31.
+	month := time.Date(issue.Year(), issue.Month()+time.Month(number), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := month.AddDate(0, 1, -1).Day()
+	due := month.AddDate(0, 0, min(paymentDay, lastDay)-1)
+
+	return c.NextBusinessDay(due)
+}
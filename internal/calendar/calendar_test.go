@@ -0,0 +1,87 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(value string) time.Time {
+	day, err := time.Parse(DateLayout, value)
+	if err != nil {
+		panic(err)
+	}
+	return day
+}
+
+func TestBusinessDay(t *testing.T) {
+	calendar, err := New([]string{"2025-05-09"}, []string{"2025-11-01"})
+	require.NoError(t, err)
+
+	assert.True(t, calendar.BusinessDay(date("2025-05-07")))
+	assert.False(t, calendar.BusinessDay(date("2025-05-09")), "holiday")
+	assert.False(t, calendar.BusinessDay(date("2025-05-10")), "Saturday")
+	assert.True(t, calendar.BusinessDay(date("2025-11-01")), "working Saturday")
+
+	var weekends *Calendar
+	assert.True(t, weekends.BusinessDay(date("2025-05-09")))
+	assert.False(t, weekends.BusinessDay(date("2025-05-11")))
+}
+
+func TestPaymentDate(t *testing.T) {
+	calendar, err := New([]string{"2025-05-01", "2025-05-02", "2025-05-08", "2025-05-09"}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		issue      string
+		paymentDay int
+		number     int
+		expected   string
+	}{
+		{"issue day", "2025-01-15", 0, 1, "2025-02-17"}, // February 15 is a Saturday.
+		{"short month", "2025-01-31", 0, 1, "2025-02-28"},
+		{"back to the payment day", "2025-01-31", 0, 2, "2025-03-31"},
+		{"leap year", "2024-01-30", 0, 1, "2024-02-29"},
+		{"payment day", "2025-01-15", 10, 3, "2025-04-10"},
+		{"payment day after the month end", "2025-01-15", 31, 3, "2025-04-30"},
+		{"holidays", "2025-04-01", 1, 1, "2025-05-05"}, // May 1 and 2 are holidays, then the weekend.
+		{"next year", "2025-12-20", 0, 12, "2026-12-21"},
+		{"long term", "2025-01-10", 0, 240, "2045-01-10"},
+	}
+
+	for _, test := range tests {
+		actual := calendar.PaymentDate(date(test.issue), test.paymentDay, test.number)
+		assert.Equal(t, test.expected, actual.Format(DateLayout), test.name)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "calendar.yml")
+	require.NoError(t, os.WriteFile(path, []byte("holidays:\n  - 2026-01-09\nworkdays:\n  - 2026-01-10\n"), 0o600))
+
+	calendar, err := Load(path)
+	require.NoError(t, err)
+	assert.False(t, calendar.BusinessDay(date("2026-01-09")))
+	assert.True(t, calendar.BusinessDay(date("2026-01-10")))
+
+	require.NoError(t, os.WriteFile(path, []byte("holidays:\n  - 09.01.2026\n"), 0o600))
+	_, err = Load(path)
+	assert.ErrorIs(t, err, ErrInvalidCalendar)
+
+	_, err = Load(filepath.Join(dir, "missing.yml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	calendar, err := Load(filepath.Join("..", "..", "config", "calendar.yml"))
+	require.NoError(t, err)
+
+	assert.False(t, calendar.BusinessDay(date("2026-01-09")))
+	assert.True(t, calendar.BusinessDay(date("2025-11-01")))
+}
@@ -0,0 +1,196 @@
+// Package export writes calculations and cached loans as CSV and XLSX spreadsheets
+// in the layout of the reference workbook docs/example_golang.xlsx, and loan offers as PDF documents.
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/models"
+)
+
+// Content types of the export formats.
+const (
+	ContentTypeCSV  = "text/csv"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	ContentTypePDF  = "application/pdf"
+)
+
+// Titles of the reference workbook sections and sheets.
+const (
+	titleParams     = "Параметры на вход"
+	titleRates      = "Справочник процентных ставок"
+	titleAggregates = "Рассчитываемые агрегаты"
+	titleCalc       = "Расчет"
+	titleSchedule   = "График платежей"
+	titleLoans      = "Кэш расчетов"
+)
+
+// Columns of the reference workbook blocks: names in C and F, values in D and G.
+const (
+	colParamName = 2 + iota
+	colParamValue
+	_
+	colAggregateName
+	colAggregateValue
+)
+
+var (
+	scheduleHeader = []string{"number", "date", "payment", "interest", "principal", "prepayment", "balance"}
+	loansHeader    = []string{"id", "object_cost", "initial_payment", "months", "program", "payment_type", "rate",
+		"loan_sum", "monthly_payment", "first_payment", "last_payment", "max_payment", "overpayment", "last_payment_date"}
+)
+
+// WriteCalculationXLSX writes the calculation with the rates of the programs and its payment schedule as a workbook.
+// The first sheet repeats the blocks of the reference workbook, the second one holds the amortization table.
+func WriteCalculationXLSX(w io.Writer, result models.CalculationResult, schedule []models.SchedulePayment, programs []models.LoanProgram) error {
+	var book workbook
+	calc := book.addSheet(titleCalc)
+	calc.width(colParamName, 22)
+	calc.width(colParamValue, 14)
+	calc.width(colAggregateName, 20)
+	calc.width(colAggregateValue, 16)
+
+	calc.set(1, colParamName, headerCell(titleParams))
+	calc.merge(1, colParamName, colParamValue)
+	for i, row := range [][2]cell{
+		{textCell("object_cost"), intCell(result.Params.ObjectCost)},
+		{textCell("initial_payment"), intCell(result.Params.InitialPayment)},
+		{textCell("months"), intCell(result.Params.Months)},
+		{textCell(calculator.ProgramID(result.Program)), boolCell(true)},
+		{textCell("payment_type"), textCell(paymentType(result.PaymentType))},
+	} {
+		calc.setRow(2+i, colParamName, row[0], row[1])
+	}
+
+	aggregates := result.Aggregates
+	calc.set(1, colAggregateName, headerCell(titleAggregates))
+	calc.merge(1, colAggregateName, colAggregateValue)
+	for i, row := range [][2]cell{
+		{textCell("rate"), percentCell(aggregates.Rate)},
+		{textCell("loan_sum"), moneyCell(aggregates.LoanSum.Decimal)},
+		{textCell("monthly_payment"), moneyCell(aggregates.MonthlyPayment.Decimal)},
+		{textCell("overpayment"), moneyCell(aggregates.Overpayment.Decimal)},
+		{textCell("last_payment_date"), dateCell(aggregates.LastPaymentDate)},
+		{textCell("first_payment"), moneyCell(aggregates.FirstPayment.Decimal)},
+		{textCell("last_payment"), moneyCell(aggregates.LastPayment.Decimal)},
+		{textCell("max_payment"), moneyCell(aggregates.MaxPayment.Decimal)},
+	} {
+		calc.setRow(2+i, colAggregateName, row[0], row[1])
+	}
+
+	calc.set(7, colParamName, headerCell(titleRates))
+	calc.merge(7, colParamName, colParamValue)
+	for i, program := range programs {
+		calc.setRow(8+i, colParamName, textCell(program.ID), percentCell(program.Rate))
+	}
+
+	table := book.addSheet(titleSchedule)
+	for col, name := range scheduleHeader {
+		table.set(0, col, headerCell(name))
+		table.width(col, 14)
+	}
+	for i, row := range schedule {
+		table.setRow(1+i, 0, intCell(row.Number), dateCell(row.Date), moneyCell(row.Payment.Decimal), moneyCell(row.Interest.Decimal),
+			moneyCell(row.Principal.Decimal), moneyCell(row.Prepayment.Decimal), moneyCell(row.Balance.Decimal))
+	}
+
+	return book.write(w)
+}
+
+// WriteCalculationCSV writes the parameters and aggregates of the calculation as name and value pairs,
+// followed by an empty line and the payment schedule with a header.
+func WriteCalculationCSV(w io.Writer, result models.CalculationResult, schedule []models.SchedulePayment) error {
+	writer := csv.NewWriter(w)
+	aggregates := result.Aggregates
+	records := [][]string{
+		{"object_cost", strconv.Itoa(result.Params.ObjectCost)},
+		{"initial_payment", strconv.Itoa(result.Params.InitialPayment)},
+		{"months", strconv.Itoa(result.Params.Months)},
+		{"program", calculator.ProgramID(result.Program)},
+		{"payment_type", paymentType(result.PaymentType)},
+		{"rate", aggregates.Rate.String()},
+		{"loan_sum", aggregates.LoanSum.StringFixed(2)},
+		{"monthly_payment", aggregates.MonthlyPayment.StringFixed(2)},
+		{"overpayment", aggregates.Overpayment.StringFixed(2)},
+		{"last_payment_date", aggregates.LastPaymentDate},
+		{"first_payment", aggregates.FirstPayment.StringFixed(2)},
+		{"last_payment", aggregates.LastPayment.StringFixed(2)},
+		{"max_payment", aggregates.MaxPayment.StringFixed(2)},
+		{},
+		scheduleHeader,
+	}
+	for _, row := range schedule {
+		records = append(records, []string{strconv.Itoa(row.Number), row.Date, row.Payment.StringFixed(2), row.Interest.StringFixed(2),
+			row.Principal.StringFixed(2), row.Prepayment.StringFixed(2), row.Balance.StringFixed(2)})
+	}
+	return writer.WriteAll(records)
+}
+
+// WriteLoansCSV writes the cached loans as a table with a header.
+func WriteLoansCSV(w io.Writer, loans []models.CachedLoan) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{loansHeader}
+	for _, loan := range loans {
+		aggregates := loan.Aggregates
+		records = append(records, []string{
+			strconv.Itoa(loan.ID),
+			strconv.Itoa(loan.Params.ObjectCost),
+			strconv.Itoa(loan.Params.InitialPayment),
+			strconv.Itoa(loan.Params.Months),
+			calculator.ProgramID(loan.Program),
+			paymentType(loan.PaymentType),
+			aggregates.Rate.String(),
+			aggregates.LoanSum.StringFixed(2),
+			aggregates.MonthlyPayment.StringFixed(2),
+			aggregates.FirstPayment.StringFixed(2),
+			aggregates.LastPayment.StringFixed(2),
+			aggregates.MaxPayment.StringFixed(2),
+			aggregates.Overpayment.StringFixed(2),
+			aggregates.LastPaymentDate,
+		})
+	}
+	return writer.WriteAll(records)
+}
+
+// WriteLoansXLSX writes the cached loans as a workbook with a single table.
+func WriteLoansXLSX(w io.Writer, loans []models.CachedLoan) error {
+	var book workbook
+	table := book.addSheet(titleLoans)
+	for col, name := range loansHeader {
+		table.set(0, col, headerCell(name))
+		table.width(col, 16)
+	}
+
+	for i, loan := range loans {
+		aggregates := loan.Aggregates
+		table.setRow(1+i, 0,
+			intCell(loan.ID),
+			intCell(loan.Params.ObjectCost),
+			intCell(loan.Params.InitialPayment),
+			intCell(loan.Params.Months),
+			textCell(calculator.ProgramID(loan.Program)),
+			textCell(paymentType(loan.PaymentType)),
+			percentCell(aggregates.Rate),
+			moneyCell(aggregates.LoanSum.Decimal),
+			moneyCell(aggregates.MonthlyPayment.Decimal),
+			moneyCell(aggregates.FirstPayment.Decimal),
+			moneyCell(aggregates.LastPayment.Decimal),
+			moneyCell(aggregates.MaxPayment.Decimal),
+			moneyCell(aggregates.Overpayment.Decimal),
+			dateCell(aggregates.LastPaymentDate),
+		)
+	}
+
+	return book.write(w)
+}
+
+// paymentType returns the payment scheme, annuity when not set.
+func paymentType(value string) string {
+	if value == "" {
+		return models.PaymentTypeAnnuity
+	}
+	return value
+}
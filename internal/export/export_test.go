@@ -0,0 +1,154 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+func testResult() models.CalculationResult {
+	return models.CalculationResult{
+		Params:  models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 2},
+		Program: models.Program{Salary: true},
+		Aggregates: models.Aggregates{
+			Rate:            decimal.NewFromInt(8),
+			LoanSum:         models.MoneyFromInt(4000000),
+			MonthlyPayment:  models.NewMoney(decimal.RequireFromString("2013355.49")),
+			Overpayment:     models.NewMoney(decimal.RequireFromString("26710.98")),
+			LastPaymentDate: "2026-12-17",
+		},
+	}
+}
+
+func testSchedule() []models.SchedulePayment {
+	return []models.SchedulePayment{
+		{Number: 1, Date: "2026-11-17", Payment: models.NewMoney(decimal.RequireFromString("2013355.49")),
+			Interest: models.NewMoney(decimal.RequireFromString("26666.67")), Principal: models.NewMoney(decimal.RequireFromString("1986688.82")),
+			Balance: models.NewMoney(decimal.RequireFromString("2013311.18"))},
+		{Number: 2, Date: "2026-12-17", Payment: models.NewMoney(decimal.RequireFromString("2026733.25")),
+			Interest: models.NewMoney(decimal.RequireFromString("13422.07")), Principal: models.NewMoney(decimal.RequireFromString("2013311.18"))},
+	}
+}
+
+// readXLSX returns the parts of the archive by name.
+func readXLSX(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		reader, openErr := file.Open()
+		require.NoError(t, openErr)
+		content, readErr := io.ReadAll(reader)
+		require.NoError(t, readErr)
+		require.NoError(t, reader.Close())
+		parts[file.Name] = string(content)
+	}
+	return parts
+}
+
+func TestWriteCalculationXLSX(t *testing.T) {
+	programs := []models.LoanProgram{
+		{ID: "salary", Rate: decimal.NewFromInt(8)},
+		{ID: "base", Rate: decimal.NewFromInt(10)},
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteCalculationXLSX(&buffer, testResult(), testSchedule(), programs))
+
+	parts := readXLSX(t, buffer.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels",
+		"xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		assert.Contains(t, parts, name)
+	}
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Расчет" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="График платежей" sheetId="2" r:id="rId2"/>`)
+
+	calc := parts["xl/worksheets/sheet1.xml"]
+	for _, expected := range []string{
+		`<c r="C2" s="1" t="inlineStr"><is><t xml:space="preserve">Параметры на вход</t></is></c>`,
+		`<c r="D3" s="0" t="n"><v>5000000</v></c>`,
+		`<c r="C6" s="0" t="inlineStr"><is><t xml:space="preserve">salary</t></is></c><c r="D6" s="0" t="b"><v>1</v></c>`,
+		`<c r="G3" s="3" t="n"><v>0.08</v></c>`,
+		`<c r="G5" s="2" t="n"><v>2013355.49</v></c>`,
+		`<c r="G7" s="4" t="n"><v>46373</v></c>`,
+		`<c r="C10" s="0" t="inlineStr"><is><t xml:space="preserve">base</t></is></c><c r="D10" s="3" t="n"><v>0.1</v></c>`,
+		`<mergeCell ref="C2:D2"/>`,
+		`<mergeCell ref="F2:G2"/>`,
+	} {
+		assert.Contains(t, calc, expected)
+	}
+
+	table := parts["xl/worksheets/sheet2.xml"]
+	assert.Contains(t, table, `<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">number</t></is></c>`)
+	assert.Contains(t, table, `<c r="B3" s="4" t="n"><v>46373</v></c><c r="C3" s="2" t="n"><v>2026733.25</v></c>`)
+	assert.Contains(t, table, `<c r="G3" s="2" t="n"><v>0.00</v></c>`)
+}
+
+func TestWriteCalculationCSV(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, WriteCalculationCSV(&buffer, testResult(), testSchedule()))
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	require.Len(t, lines, 17)
+	assert.Equal(t, "object_cost,5000000", lines[0])
+	assert.Equal(t, "program,salary", lines[3])
+	assert.Equal(t, "payment_type,annuity", lines[4])
+	assert.Equal(t, "rate,8", lines[5])
+	assert.Equal(t, "monthly_payment,2013355.49", lines[7])
+	assert.Equal(t, "", lines[13])
+	assert.Equal(t, "number,date,payment,interest,principal,prepayment,balance", lines[14])
+	assert.Equal(t, "2,2026-12-17,2026733.25,13422.07,2013311.18,0.00,0.00", lines[16])
+}
+
+func TestWriteLoans(t *testing.T) {
+	loans := []models.CachedLoan{
+		{ID: 3, CalculationResult: testResult()},
+		{ID: 7, CalculationResult: models.CalculationResult{PaymentType: models.PaymentTypeDifferentiated, Program: models.Program{Base: true}}},
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteLoansCSV(&buffer, loans))
+	records, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, loansHeader, records[0])
+	assert.Equal(t, []string{"3", "5000000", "1000000", "2", "salary", "annuity", "8", "4000000.00", "2013355.49",
+		"0.00", "0.00", "0.00", "26710.98", "2026-12-17"}, records[1])
+	assert.Equal(t, "base", records[2][4])
+	assert.Equal(t, models.PaymentTypeDifferentiated, records[2][5])
+
+	buffer.Reset()
+	require.NoError(t, WriteLoansXLSX(&buffer, loans))
+	sheet := readXLSX(t, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A2" s="0" t="n"><v>3</v></c>`)
+	assert.Contains(t, sheet, `<c r="N2" s="4" t="n"><v>46373</v></c>`)
+	assert.Contains(t, sheet, `<c r="N3" s="0" t="inlineStr"><is><t xml:space="preserve"></t></is></c>`)
+}
+
+func TestCellRef(t *testing.T) {
+	tests := []struct {
+		row, col int
+		expected string
+	}{
+		{0, 0, "A1"},
+		{1, 25, "Z2"},
+		{9, 26, "AA10"},
+		{0, 701, "ZZ1"},
+		{0, 702, "AAA1"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, cellRef(test.row, test.col))
+	}
+}
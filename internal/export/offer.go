@@ -0,0 +1,160 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/models"
+)
+
+// offerPayments is the number of payments shown from the beginning and from the end of a longer schedule.
+const offerPayments = 12
+
+// Layout of the offer: the right edge of the summary values and of the schedule columns.
+const (
+	offerValueEdge = 330
+	offerTableEdge = pageWidth - pageMargin
+)
+
+// offerColumns are the schedule table columns with their right edges, the date column is aligned left.
+var offerColumns = []struct {
+	title string
+	edge  float64
+}{
+	{"No", 75}, {"Date", 90}, {"Payment", 250}, {"Interest", 350}, {"Principal", 450}, {"Balance", offerTableEdge},
+}
+
+// WriteOfferPDF writes the printable loan offer: the program with its rate, the loan parameters, the payment summary
+// and the first and last payments of the schedule. The program name is taken from the programs.
+func WriteOfferPDF(w io.Writer, result models.CalculationResult, schedule []models.SchedulePayment, programs []models.LoanProgram) error {
+	id := calculator.ProgramID(result.Program)
+	name := id
+	for _, program := range programs {
+		if program.ID == id && program.Name != "" {
+			name = fmt.Sprintf("%s (%s)", program.Name, id)
+		}
+	}
+
+	document := newPDFDocument("Mortgage loan offer")
+	document.text(pageMargin, document.line(24), fontBold, 18, "Mortgage loan offer")
+	document.text(pageMargin, document.line(22), fontRegular, 12, "Program: "+name)
+	document.text(pageMargin, document.line(16), fontRegular, 12, fmt.Sprintf("Interest rate: %s%% per annum", result.Aggregates.Rate))
+
+	writeOfferSummary(document, result)
+	writeOfferSchedule(document, schedule)
+
+	document.line(10)
+	document.text(pageMargin, document.line(12), fontRegular, 8, "The calculation is indicative and does not constitute a public offer.")
+	return document.write(w)
+}
+
+// writeOfferSummary writes the loan parameters and the payment summary with the affordability of the borrower.
+func writeOfferSummary(document *pdfDocument, result models.CalculationResult) {
+	aggregates := result.Aggregates
+	total := aggregates.LoanSum.Add(aggregates.Overpayment.Decimal)
+
+	sections := []struct {
+		title string
+		rows  [][2]string
+	}{
+		{"Loan parameters", [][2]string{
+			{"Object cost", formatRubles(decimal.NewFromInt(int64(result.Params.ObjectCost)))},
+			{"Initial payment", formatRubles(decimal.NewFromInt(int64(result.Params.InitialPayment)))},
+			{"Loan sum", formatRubles(aggregates.LoanSum.Decimal)},
+			{"Term", fmt.Sprintf("%d months", result.Params.Months)},
+			{"Payment type", paymentType(result.PaymentType)},
+		}},
+		{"Payment summary", [][2]string{
+			{"Monthly payment", formatRubles(aggregates.MonthlyPayment.Decimal)},
+			{"First payment", formatRubles(aggregates.FirstPayment.Decimal)},
+			{"Last payment", formatRubles(aggregates.LastPayment.Decimal)},
+			{"Maximum payment", formatRubles(aggregates.MaxPayment.Decimal)},
+			{"Overpayment", formatRubles(aggregates.Overpayment.Decimal)},
+			{"Total payments", formatRubles(total)},
+			{"Last payment date", aggregates.LastPaymentDate},
+		}},
+	}
+	if affordability := result.Affordability; affordability != nil {
+		sections[1].rows = append(sections[1].rows,
+			[2]string{"Debt-to-income ratio", affordability.PDN.StringFixed(2) + "%"},
+			[2]string{"Affordability verdict", affordability.Verdict})
+	}
+
+	for _, section := range sections {
+		document.line(10)
+		document.text(pageMargin, document.line(16), fontBold, 12, section.title)
+		for _, row := range section.rows {
+			y := document.line(15)
+			document.text(pageMargin, y, fontRegular, 10, row[0])
+			document.textRight(offerValueEdge, y, fontRegular, 10, row[1])
+		}
+	}
+}
+
+// writeOfferSchedule writes the table of the first and last payments, or of all payments for a short schedule.
+func writeOfferSchedule(document *pdfDocument, schedule []models.SchedulePayment) {
+	title := "Payment schedule"
+	rows := schedule
+	truncated := len(schedule) > 2*offerPayments
+	if truncated {
+		title = fmt.Sprintf("Payment schedule: first and last %d of %d payments", offerPayments, len(schedule))
+		rows = append(append([]models.SchedulePayment(nil), schedule[:offerPayments]...), schedule[len(schedule)-offerPayments:]...)
+	}
+
+	document.line(10)
+	document.text(pageMargin, document.line(16), fontBold, 12, title)
+
+	y := document.line(15)
+	for i, column := range offerColumns {
+		if i == 1 {
+			document.text(column.edge, y, fontBold, 9, column.title)
+			continue
+		}
+		document.textRight(column.edge, y, fontBold, 9, column.title)
+	}
+	document.rule(pageMargin, offerTableEdge, y-4)
+
+	for i, row := range rows {
+		if truncated && i == offerPayments {
+			document.text(offerColumns[1].edge, document.line(13), fontRegular, 9, "...")
+		}
+
+		y = document.line(13)
+		values := []string{fmt.Sprint(row.Number), row.Date, formatAmount(row.Payment.Decimal), formatAmount(row.Interest.Decimal),
+			formatAmount(row.Principal.Decimal), formatAmount(row.Balance.Decimal)}
+		for col, value := range values {
+			if col == 1 {
+				document.text(offerColumns[col].edge, y, fontRegular, 9, value)
+				continue
+			}
+			document.textRight(offerColumns[col].edge, y, fontRegular, 9, value)
+		}
+	}
+}
+
+// formatRubles formats the amount with the currency.
+func formatRubles(amount decimal.Decimal) string {
+	return formatAmount(amount) + " RUB"
+}
+
+// formatAmount formats the amount with two decimal places and thousands separated by spaces.
+func formatAmount(amount decimal.Decimal) string {
+	digits := amount.Abs().StringFixed(2)
+	whole, fraction := digits[:len(digits)-3], digits[len(digits)-3:]
+
+	var grouped strings.Builder
+	if amount.IsNegative() {
+		grouped.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(' ')
+		}
+		grouped.WriteRune(digit)
+	}
+	return grouped.String() + fraction
+}
@@ -0,0 +1,176 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Geometry of the A4 page in points.
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	pageMargin = 50
+)
+
+// Fonts of the standard set, which PDF readers provide without embedding.
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+// helveticaWidths holds the glyph widths of Helvetica in thousandths of the font size for the characters
+// of numbers and dates; the other characters are estimated with defaultGlyphWidth.
+var helveticaWidths = map[rune]float64{
+	' ': 278, '.': 278, ',': 278, '-': 333, '%': 889,
+	'0': 556, '1': 556, '2': 556, '3': 556, '4': 556, '5': 556, '6': 556, '7': 556, '8': 556, '9': 556,
+}
+
+const defaultGlyphWidth = 556
+
+// winAnsiSymbols maps the characters outside Latin-1 to their WinAnsiEncoding codes.
+var winAnsiSymbols = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// cyrillicLetters transliterates the lowercase Cyrillic letters, as the standard fonts have no Cyrillic glyphs.
+var cyrillicLetters = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i", 'й': "y",
+	'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f",
+	'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'№': "No.",
+}
+
+// pdfDocument is a minimal PDF writer placing text and lines on A4 pages with the standard Helvetica fonts.
+type pdfDocument struct {
+	title string
+	pages []*bytes.Buffer // Content streams of the pages.
+	y     float64         // Baseline of the last reserved line on the current page.
+}
+
+func newPDFDocument(title string) *pdfDocument {
+	document := &pdfDocument{title: title}
+	document.addPage()
+	return document
+}
+
+func (d *pdfDocument) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - pageMargin
+}
+
+// line reserves the height for the next line and returns its baseline, starting a new page when the current one is full.
+func (d *pdfDocument) line(height float64) float64 {
+	if d.y-height < pageMargin {
+		d.addPage()
+	}
+	d.y -= height
+	return d.y
+}
+
+// text draws the text with its left edge at x on the baseline y of the current page.
+func (d *pdfDocument) text(x, y float64, font string, size float64, value string) {
+	fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(value))
+}
+
+// textRight draws the text with its right edge at x.
+func (d *pdfDocument) textRight(x, y float64, font string, size float64, value string) {
+	d.text(x-textWidth(value, size), y, font, size, value)
+}
+
+// rule draws a thin horizontal line from x1 to x2 at y.
+func (d *pdfDocument) rule(x1, x2, y float64) {
+	fmt.Fprintf(d.pages[len(d.pages)-1], "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y, x2, y)
+}
+
+// write encodes the document with the cross-reference table.
+// Objects: 1 catalog, 2 page tree, 3 and 4 fonts, 5 information, then a page and its content for each page.
+func (d *pdfDocument) write(w io.Writer) error {
+	const firstPageObject = 6
+
+	var buffer bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buffer.Len())
+		fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// The binary comment marks the file as binary for transfer tools.
+	buffer.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObject+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (sbermortgagecalculator) >>", pdfString(d.title)))
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, fontRegular, fontBold, firstPageObject+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buffer.Len()
+	fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := buffer.WriteTo(w)
+	return err
+}
+
+// pdfString encodes the text as the content of a literal string in WinAnsiEncoding.
+// Cyrillic is transliterated and the other characters missing in the encoding are replaced by a question mark.
+func pdfString(value string) string {
+	var buffer strings.Builder
+	for _, char := range value {
+		if latin, ok := cyrillicLetters[unicode.ToLower(char)]; ok {
+			if unicode.IsUpper(char) && latin != "" {
+				latin = strings.ToUpper(latin[:1]) + latin[1:]
+			}
+			buffer.WriteString(latin)
+			continue
+		}
+
+		code, ok := winAnsiSymbols[char]
+		switch {
+		case ok:
+		case char < 0x7f || char >= 0xa0 && char <= 0xff:
+			code = byte(char)
+		default:
+			code = '?'
+		}
+
+		switch {
+		case code == '(' || code == ')' || code == '\\':
+			buffer.WriteByte('\\')
+			buffer.WriteByte(code)
+		case code < 0x20 || code >= 0x7f:
+			fmt.Fprintf(&buffer, "\\%03o", code)
+		default:
+			buffer.WriteByte(code)
+		}
+	}
+	return buffer.String()
+}
+
+// textWidth estimates the width of the text in points.
+func textWidth(value string, size float64) float64 {
+	width := 0.0
+	for _, char := range value {
+		glyph, ok := helveticaWidths[char]
+		if !ok {
+			glyph = defaultGlyphWidth
+		}
+		width += glyph
+	}
+	return width * size / 1000
+}
@@ -0,0 +1,129 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sbermortgagecalculator/internal/models"
+)
+
+// assertPDFStructure checks the header, the trailer and that the cross-reference table points at the objects.
+func assertPDFStructure(t *testing.T, data []byte) {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	require.NotNil(t, match)
+	xref, err := strconv.Atoi(string(match[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(data[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
+
+func TestWriteOfferPDF(t *testing.T) {
+	result := testResult()
+	result.Affordability = &models.Affordability{PDN: decimal.RequireFromString("42.5"), Verdict: models.VerdictApproved}
+	programs := []models.LoanProgram{{ID: "salary", Name: "Программа для корпоративных клиентов"}}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteOfferPDF(&buffer, result, testSchedule(), programs))
+
+	data := buffer.Bytes()
+	assertPDFStructure(t, data)
+	for _, expected := range []string{
+		"/Count 1",
+		"(Program: Programma dlya korporativnykh klientov \\(salary\\)) Tj",
+		"(Interest rate: 8% per annum) Tj",
+		"(4 000 000.00 RUB) Tj",
+		"(2 013 355.49 RUB) Tj",
+		"(42.50%) Tj",
+		"(approved) Tj",
+		"(Payment schedule) Tj",
+		"(2026-12-17) Tj",
+		"(2 026 733.25) Tj",
+	} {
+		assert.Contains(t, string(data), expected)
+	}
+}
+
+func TestWriteOfferPDF_LongSchedule(t *testing.T) {
+	schedule := make([]models.SchedulePayment, 240)
+	for i := range schedule {
+		schedule[i] = models.SchedulePayment{Number: i + 1, Date: fmt.Sprintf("payment-%d", i+1)}
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteOfferPDF(&buffer, testResult(), schedule, nil))
+
+	data := string(buffer.Bytes())
+	assert.Contains(t, data, "(Program: salary) Tj")
+	assert.Contains(t, data, "(Payment schedule: first and last 12 of 240 payments) Tj")
+	assert.Contains(t, data, "(payment-12) Tj")
+	assert.Contains(t, data, "(...) Tj")
+	assert.Contains(t, data, "(payment-229) Tj")
+	assert.NotContains(t, data, "(payment-13) Tj")
+	assert.NotContains(t, data, "(payment-228) Tj")
+}
+
+func TestPDFDocument_Pages(t *testing.T) {
+	document := newPDFDocument("Pages")
+	for i := 0; i < 100; i++ {
+		document.text(pageMargin, document.line(12), fontRegular, 10, strconv.Itoa(i))
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, document.write(&buffer))
+	assertPDFStructure(t, buffer.Bytes())
+	assert.Len(t, document.pages, 2)
+	assert.Contains(t, buffer.String(), "/Kids [6 0 R 8 0 R] /Count 2")
+}
+
+func TestPDFString(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{`(a\b)`, `\(a\\b\)`},
+		{"Щука и Ёж", "Shchuka i Ezh"},
+		{"Объект №1", "Obekt No.1"},
+		{"5 000 — 10 €", `5 000 \227 10 \200`},
+		{"café", `caf\351`},
+		{"日本", "??"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, pdfString(test.value), test.value)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		expected string
+	}{
+		{"0", "0.00"},
+		{"999.5", "999.50"},
+		{"1000", "1 000.00"},
+		{"4029824.005", "4 029 824.01"},
+		{"-123456", "-123 456.00"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, formatAmount(decimal.RequireFromString(test.amount)))
+	}
+}
@@ -0,0 +1,271 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Cell styles defined in stylesXML.
+const (
+	styleDefault = iota
+	styleHeader
+	styleMoney
+	stylePercent
+	styleDate
+)
+
+// excelEpoch is the day zero of the spreadsheet date serial numbers.
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// cell is a single spreadsheet value with its style.
+type cell struct {
+	value string // Encoded value: text, number or 0/1 for booleans.
+	kind  string // Cell type attribute: inlineStr, n or b.
+	style int
+}
+
+func textCell(value string) cell {
+	return cell{value: value, kind: "inlineStr"}
+}
+
+func headerCell(value string) cell {
+	return cell{value: value, kind: "inlineStr", style: styleHeader}
+}
+
+func intCell(value int) cell {
+	return cell{value: strconv.Itoa(value), kind: "n"}
+}
+
+func moneyCell(value decimal.Decimal) cell {
+	return cell{value: value.StringFixed(2), kind: "n", style: styleMoney}
+}
+
+// percentCell converts the rate in percent to the fraction shown with the percent format, as in the reference workbook.
+func percentCell(rate decimal.Decimal) cell {
+	return cell{value: rate.Div(decimal.NewFromInt(100)).String(), kind: "n", style: stylePercent}
+}
+
+func boolCell(value bool) cell {
+	if value {
+		return cell{value: "1", kind: "b"}
+	}
+	return cell{value: "0", kind: "b"}
+}
+
+// dateCell converts the date in the 2006-01-02 layout to a serial number, keeping unparsable dates as text.
+func dateCell(value string) cell {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return textCell(value)
+	}
+	return cell{value: strconv.Itoa(int(date.Sub(excelEpoch).Hours() / 24)), kind: "n", style: styleDate}
+}
+
+// sheet is a worksheet with cells addressed by zero-based row and column.
+type sheet struct {
+	name   string
+	cells  map[int]map[int]cell
+	merges []string
+	widths map[int]float64
+}
+
+// set places the cell, the reference is zero-based.
+func (s *sheet) set(row, col int, value cell) {
+	if s.cells[row] == nil {
+		s.cells[row] = make(map[int]cell)
+	}
+	s.cells[row][col] = value
+}
+
+// setRow places the cells to the row starting from the column.
+func (s *sheet) setRow(row, col int, values ...cell) {
+	for i, value := range values {
+		s.set(row, col+i, value)
+	}
+}
+
+// merge joins the cells of the row from the first to the last column.
+func (s *sheet) merge(row, first, last int) {
+	s.merges = append(s.merges, cellRef(row, first)+":"+cellRef(row, last))
+}
+
+// width sets the width of the column in characters.
+func (s *sheet) width(col int, width float64) {
+	s.widths[col] = width
+}
+
+// workbook is a minimal XLSX document with inline strings and a fixed set of styles.
+type workbook struct {
+	sheets []*sheet
+}
+
+func (w *workbook) addSheet(name string) *sheet {
+	added := &sheet{name: name, cells: make(map[int]map[int]cell), widths: make(map[int]float64)}
+	w.sheets = append(w.sheets, added)
+	return added
+}
+
+// write encodes the workbook as an XLSX (Office Open XML) archive.
+func (w *workbook) write(output io.Writer) error {
+	archive := zip.NewWriter(output)
+
+	type part struct {
+		name    string
+		content []byte
+	}
+	parts := []part{
+		{"[Content_Types].xml", w.contentTypes()},
+		{"_rels/.rels", []byte(rootRelsXML)},
+		{"xl/workbook.xml", w.workbookXML()},
+		{"xl/_rels/workbook.xml.rels", w.workbookRels()},
+		{"xl/styles.xml", []byte(stylesXML)},
+	}
+	for i, s := range w.sheets {
+		parts = append(parts, part{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.xml()})
+	}
+
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = file.Write(part.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func (w *workbook) contentTypes() []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	buffer.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	buffer.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	buffer.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	buffer.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	buffer.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range w.sheets {
+		fmt.Fprintf(&buffer, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	buffer.WriteString(`</Types>`)
+	return buffer.Bytes()
+}
+
+func (w *workbook) workbookXML() []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	buffer.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, s := range w.sheets {
+		fmt.Fprintf(&buffer, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(s.name), i+1, i+1)
+	}
+	buffer.WriteString(`</sheets></workbook>`)
+	return buffer.Bytes()
+}
+
+func (w *workbook) workbookRels() []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	buffer.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range w.sheets {
+		fmt.Fprintf(&buffer, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&buffer, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(w.sheets)+1)
+	buffer.WriteString(`</Relationships>`)
+	return buffer.Bytes()
+}
+
+func (s *sheet) xml() []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	buffer.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+
+	if len(s.widths) > 0 {
+		buffer.WriteString(`<cols>`)
+		for _, col := range sortedKeys(s.widths) {
+			fmt.Fprintf(&buffer, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, col+1, col+1, s.widths[col])
+		}
+		buffer.WriteString(`</cols>`)
+	}
+
+	buffer.WriteString(`<sheetData>`)
+	for _, row := range sortedKeys(s.cells) {
+		fmt.Fprintf(&buffer, `<row r="%d">`, row+1)
+		for _, col := range sortedKeys(s.cells[row]) {
+			value := s.cells[row][col]
+			fmt.Fprintf(&buffer, `<c r="%s" s="%d" t="%s">`, cellRef(row, col), value.style, value.kind)
+			if value.kind == "inlineStr" {
+				fmt.Fprintf(&buffer, `<is><t xml:space="preserve">%s</t></is>`, escape(value.value))
+			} else {
+				fmt.Fprintf(&buffer, `<v>%s</v>`, value.value)
+			}
+			buffer.WriteString(`</c>`)
+		}
+		buffer.WriteString(`</row>`)
+	}
+	buffer.WriteString(`</sheetData>`)
+
+	if len(s.merges) > 0 {
+		fmt.Fprintf(&buffer, `<mergeCells count="%d">`, len(s.merges))
+		for _, ref := range s.merges {
+			fmt.Fprintf(&buffer, `<mergeCell ref="%s"/>`, ref)
+		}
+		buffer.WriteString(`</mergeCells>`)
+	}
+
+	buffer.WriteString(`</worksheet>`)
+	return buffer.Bytes()
+}
+
+// cellRef converts the zero-based row and column to the A1 reference.
+func cellRef(row, col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row+1)
+}
+
+func escape(value string) string {
+	var buffer bytes.Buffer
+	_ = xml.EscapeText(&buffer, []byte(value))
+	return buffer.String()
+}
+
+func sortedKeys[V any](values map[int]V) []int {
+	keys := make([]int, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// stylesXML defines the cell styles in the order of the style constants: default, bold header,
+// money (#,##0.00), percent (0.00%) and date (built-in short date).
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="12"/><name val="Calibri"/></font><font><b/><sz val="12"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="10" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
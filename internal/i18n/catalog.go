@@ -0,0 +1,100 @@
+package i18n
+
+// DefaultCatalog returns the built-in messages. The English calculation errors are the texts of the calculator errors,
+// so the catalogue has no error. keys for English, and the English program names come from the configuration.
+func DefaultCatalog() Catalog {
+	return Catalog{
+		English: {
+			"http.method_not_allowed":      "Only %s method is allowed",
+			"http.invalid_json":            "Invalid JSON format",
+			"http.read_body_failed":        "Failed to read request body",
+			"http.body_too_large":          "Request body exceeds %d bytes",
+			"http.unsupported_api_version": "unsupported API version",
+			"http.unsupported_media_type":  "Content-Type must be %s",
+			"http.validation_failed":       "Validation failed",
+			"http.calculation_error":       "Calculation error: %s",
+			"http.save_failed":             "Failed to save calculation",
+			"http.export_failed":           "Failed to export calculation",
+			"http.read_cache_failed":       "Failed to read cache",
+			"http.clear_cache_failed":      "Failed to clear cache",
+			"http.access_cache_failed":     "Failed to access cache",
+			"http.empty_cache":             "empty cache",
+			"http.invalid_loan_id":         "invalid loan id",
+			"http.loan_not_found":          "loan %d not found",
+			"http.invalid_query":           "invalid query parameter: %s",
+
+			"field.not_positive":         "must be greater than zero",
+			"field.negative":             "must not be negative",
+			"field.too_large":            "must not exceed %d",
+			"field.not_below_cost":       "must be less than object_cost",
+			"field.after_term":           "must not exceed months",
+			"field.unknown_payment_type": "must be annuity or differentiated",
+			"field.unknown_strategy":     "must be reduce_term or reduce_payment",
+			"field.unknown_field":        "is not a field of the request",
+			"field.invalid_type":         "must be %s, got %s",
+			"field.invalid_date":         "must be a date in the YYYY-MM-DD format",
+		},
+		Russian: {
+			"http.method_not_allowed":      "Разрешен только метод %s",
+			"http.invalid_json":            "Неверный формат JSON",
+			"http.read_body_failed":        "Не удалось прочитать тело запроса",
+			"http.body_too_large":          "Тело запроса больше %d байт",
+			"http.unsupported_api_version": "неподдерживаемая версия API",
+			"http.unsupported_media_type":  "Content-Type должен быть %s",
+			"http.validation_failed":       "Ошибка проверки запроса",
+			"http.calculation_error":       "Ошибка расчета: %s",
+			"http.save_failed":             "Не удалось сохранить расчет",
+			"http.export_failed":           "Не удалось выгрузить расчет",
+			"http.read_cache_failed":       "Не удалось прочитать кэш",
+			"http.clear_cache_failed":      "Не удалось очистить кэш",
+			"http.access_cache_failed":     "Не удалось обратиться к кэшу",
+			"http.empty_cache":             "кэш пуст",
+			"http.invalid_loan_id":         "неверный идентификатор расчета",
+			"http.loan_not_found":          "расчет %d не найден",
+			"http.invalid_query":           "неверный параметр запроса: %s",
+
+			"error.no_program_selected":         "выберите программу",
+			"error.multiple_programs":           "выберите только 1 программу",
+			"error.unknown_program":             "неизвестная кредитная программа",
+			"error.initial_payment_too_low":     "первоначальный взнос должен быть не меньше минимума программы",
+			"error.debt_load_too_high":          "показатель долговой нагрузки превышает допустимый",
+			"error.invalid_borrower":            "доход заемщика должен быть положительным, обязательства и иждивенцы не отрицательными",
+			"error.months_out_of_range":         "срок кредита в месяцах вне ограничений программы",
+			"error.loan_sum_out_of_range":       "сумма кредита вне ограничений программы",
+			"error.months_not_positive":         "срок кредита в месяцах должен быть положительным",
+			"error.loan_sum_not_positive":       "сумма кредита должна быть больше нуля",
+			"error.division_by_zero":            "неопределенное поведение: деление на ноль",
+			"error.unknown_payment_type":        "тип платежа должен быть annuity или differentiated",
+			"error.no_eligible_programs":        "нет подходящих кредитных программ",
+			"error.empty_batch":                 "в пакете нет запросов",
+			"error.batch_too_large":             "пакет превышает максимальный размер",
+			"error.prepayment_month":            "месяц досрочного погашения должен быть в пределах срока кредита",
+			"error.prepayment_amount":           "сумма досрочного погашения должна быть больше нуля",
+			"error.prepayment_period":           "период повторения досрочного погашения не должен быть отрицательным",
+			"error.unknown_prepayment_strategy": "стратегия досрочного погашения должна быть reduce_term или reduce_payment",
+			"error.no_prepayments":              "добавьте хотя бы одно досрочное погашение",
+			"error.target_payment_not_positive": "целевой ежемесячный платеж должен быть больше нуля",
+			"error.reverse_target":              "укажите либо срок кредита, либо сумму кредита",
+			"error.invalid_request":             "запрос содержит неверные поля",
+			"error.payment_below_interest":      "целевой ежемесячный платеж не покрывает проценты по кредиту",
+			"error.program_not_financed":        "программа требует первоначальный взнос в размере всей стоимости объекта",
+			"error.invalid_issue_date":          "дата выдачи кредита должна быть в формате ГГГГ-ММ-ДД",
+			"error.no_key_rate":                 "ключевая ставка на период платежа неизвестна",
+
+			"field.not_positive":         "должно быть больше нуля",
+			"field.negative":             "не должно быть отрицательным",
+			"field.too_large":            "не должно превышать %d",
+			"field.not_below_cost":       "должен быть меньше object_cost",
+			"field.after_term":           "не должен превышать months",
+			"field.unknown_payment_type": "должен быть annuity или differentiated",
+			"field.unknown_strategy":     "должна быть reduce_term или reduce_payment",
+			"field.unknown_field":        "не является полем запроса",
+			"field.invalid_type":         "должно быть типа %s, получено %s",
+			"field.invalid_date":         "должна быть датой в формате ГГГГ-ММ-ДД",
+
+			"program.salary":   "Программа для корпоративных клиентов",
+			"program.military": "Военная ипотека",
+			"program.base":     "Базовая программа",
+		},
+	}
+}
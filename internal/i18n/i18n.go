@@ -0,0 +1,157 @@
+// Package i18n translates the messages of the responses by their stable keys.
+//
+// The keys are grouped by prefix: http. for the errors of the handlers, error. followed by the calculator error code,
+// field. for the errors of the request fields and program. followed by the program identifier for the display names.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported languages.
+const (
+	English = "en"
+	Russian = "ru"
+
+	// DefaultLanguage is used when the client accepts none of the catalogue languages.
+	// The messages required by the specification are in English.
+	DefaultLanguage = English
+)
+
+// Key prefixes of the message groups.
+const (
+	PrefixError   = "error."
+	PrefixField   = "field."
+	PrefixProgram = "program."
+)
+
+// ErrInvalidCatalog is returned for a catalogue file with an empty language or message key.
+var ErrInvalidCatalog = errors.New("invalid message catalogue")
+
+// Catalog holds the message templates by language and key. The templates are formatted with fmt.Sprintf.
+type Catalog map[string]map[string]string
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = DefaultCatalog()
+)
+
+// SetCatalog adds the messages of the catalogue to the built-in ones, replacing the messages with the same key.
+// New languages become available to the clients.
+func SetCatalog(extra Catalog) error {
+	merged := DefaultCatalog()
+	for language, messages := range extra {
+		if language == "" {
+			return fmt.Errorf("%w: empty language", ErrInvalidCatalog)
+		}
+
+		language = strings.ToLower(language)
+		if merged[language] == nil {
+			merged[language] = make(map[string]string, len(messages))
+		}
+		for key, message := range messages {
+			if key == "" {
+				return fmt.Errorf("%w: empty key in %q", ErrInvalidCatalog, language)
+			}
+			merged[language][key] = message
+		}
+	}
+
+	catalogMu.Lock()
+	catalog = merged
+	catalogMu.Unlock()
+	return nil
+}
+
+// LoadFile reads the catalogue from the YAML file with the messages by language and key.
+// The error wraps os.ErrNotExist if the file does not exist.
+func LoadFile(path string) (Catalog, error) {
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message catalogue: %w", err)
+	}
+
+	var loaded Catalog
+	if err = yaml.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message catalogue: %w", err)
+	}
+	return loaded, nil
+}
+
+// Language selects the catalogue language with the highest quality in the Accept-Language header.
+// A regional tag such as ru-RU matches its primary language. DefaultLanguage is returned when
+// the header is missing or lists no catalogue language.
+func Language(acceptLanguage string) string {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	language, quality := DefaultLanguage, 0.0
+	for _, accepted := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(accepted), ";")
+		candidate := matchLanguage(strings.ToLower(strings.TrimSpace(tag)))
+		if candidate == "" {
+			continue
+		}
+
+		weight := 1.0
+		if raw, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			var err error
+			if weight, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if weight > quality {
+			language, quality = candidate, weight
+		}
+	}
+	return language
+}
+
+// matchLanguage returns the catalogue language of the tag, or an empty string if there is none.
+// The caller holds catalogMu.
+func matchLanguage(tag string) string {
+	if tag == "*" {
+		return DefaultLanguage
+	}
+	if _, ok := catalog[tag]; ok {
+		return tag
+	}
+	if primary, _, found := strings.Cut(tag, "-"); found {
+		if _, ok := catalog[primary]; ok {
+			return primary
+		}
+	}
+	return ""
+}
+
+// Message formats the message of the key in the language with the arguments. Missing messages fall back
+// to DefaultLanguage, and unknown keys are returned as is.
+func Message(language, key string, args ...any) string {
+	template, ok := Lookup(language, key)
+	if !ok {
+		if template, ok = Lookup(DefaultLanguage, key); !ok {
+			template = key
+		}
+	}
+
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// Lookup returns the message template of the key in the language, without falling back to other languages.
+func Lookup(language, key string) (string, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	template, ok := catalog[language][key]
+	return template, ok
+}
@@ -0,0 +1,84 @@
+package i18n
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", English},
+		{"ru", Russian},
+		{"ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7", Russian},
+		{"en-US,en;q=0.9,ru;q=0.8", English},
+		{"de-DE, ru;q=0.5", Russian},
+		{"ru;q=0.3, EN;q=0.7", English},
+		{"de, fr", English},
+		{"*", English},
+		{"ru;q=abc", English},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, Language(test.header), test.header)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, "Only POST method is allowed", Message(English, "http.method_not_allowed", "POST"))
+	assert.Equal(t, "Разрешен только метод POST", Message(Russian, "http.method_not_allowed", "POST"))
+	assert.Equal(t, "Invalid JSON format", Message("de", "http.invalid_json"), "missing languages fall back to English")
+	assert.Equal(t, "unknown key", Message(Russian, "unknown key"))
+
+	_, ok := Lookup(English, PrefixError+"no_program_selected")
+	assert.False(t, ok, "English calculation errors are the texts of the errors")
+	name, ok := Lookup(Russian, PrefixProgram+"military")
+	assert.True(t, ok)
+	assert.Equal(t, "Военная ипотека", name)
+}
+
+func TestDefaultCatalog_Complete(t *testing.T) {
+	catalog := DefaultCatalog()
+	for key := range catalog[English] {
+		assert.Contains(t, catalog[Russian], key)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	defer func() {
+		require.NoError(t, SetCatalog(nil))
+	}()
+
+	path := filepath.Join(t.TempDir(), "messages.yml")
+	content := `
+ru:
+  program.family: Семейная ипотека
+  http.invalid_json: Некорректный JSON
+de:
+  http.invalid_json: Ungültiges JSON
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	catalog, err := LoadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, SetCatalog(catalog))
+
+	assert.Equal(t, "Семейная ипотека", Message(Russian, PrefixProgram+"family"))
+	assert.Equal(t, "Некорректный JSON", Message(Russian, "http.invalid_json"))
+	assert.Equal(t, "Разрешен только метод GET", Message(Russian, "http.method_not_allowed", "GET"), "built-in messages are kept")
+	assert.Equal(t, "de", Language("de-AT"))
+	assert.Equal(t, "Ungültiges JSON", Message("de", "http.invalid_json"))
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	assert.ErrorIs(t, SetCatalog(Catalog{"": {"key": "message"}}), ErrInvalidCatalog)
+	assert.ErrorIs(t, SetCatalog(Catalog{"ru": {"": "message"}}), ErrInvalidCatalog)
+}
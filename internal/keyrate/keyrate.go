@@ -0,0 +1,94 @@
+// Package keyrate tells the key rate of the central bank on a date, the base of the floating loan rates.
+package keyrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+
+	"sbermortgagecalculator/internal/calendar"
+)
+
+// ErrInvalidSchedule is returned for a key rate schedule with a malformed or repeated date or a negative rate.
+var ErrInvalidSchedule = errors.New("invalid key rate schedule")
+
+// Change is the key rate taking effect on the date.
+type Change struct {
+	Date string          `yaml:"date"` // Effective date in the YYYY-MM-DD format.
+	Rate decimal.Decimal `yaml:"rate"` // Annual rate in percent.
+}
+
+// File is the key rate file with the changes of the rate, including the forecast ones.
+type File struct {
+	Rates []Change `yaml:"rates"`
+}
+
+// Schedule holds the key rate changes in the order of their dates.
+type Schedule struct {
+	dates []time.Time
+	rates []decimal.Decimal
+}
+
+// New creates the schedule of the key rate changes given in any order.
+func New(changes []Change) (*Schedule, error) {
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: no rates", ErrInvalidSchedule)
+	}
+
+	sorted := append([]Change(nil), changes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+
+	schedule := &Schedule{
+		dates: make([]time.Time, 0, len(sorted)),
+		rates: make([]decimal.Decimal, 0, len(sorted)),
+	}
+	for _, change := range sorted {
+		date, err := time.Parse(calendar.DateLayout, change.Date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a YYYY-MM-DD date", ErrInvalidSchedule, change.Date)
+		}
+		if last := len(schedule.dates) - 1; last >= 0 && schedule.dates[last].Equal(date) {
+			return nil, fmt.Errorf("%w: duplicate date %s", ErrInvalidSchedule, change.Date)
+		}
+		if change.Rate.IsNegative() {
+			return nil, fmt.Errorf("%w: negative rate on %s", ErrInvalidSchedule, change.Date)
+		}
+		schedule.dates = append(schedule.dates, date)
+		schedule.rates = append(schedule.rates, change.Rate)
+	}
+	return schedule, nil
+}
+
+// Load reads the key rate schedule from the YAML file.
+func Load(path string) (*Schedule, error) {
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key rate schedule: %w", err)
+	}
+
+	var file File
+	if err = yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal key rate schedule: %w", err)
+	}
+	return New(file.Rates)
+}
+
+// Rate returns the key rate in effect on the day: the rate of the last change on or before the day.
+// It reports false for a nil schedule and for the days before the first change.
+func (s *Schedule) Rate(day time.Time) (decimal.Decimal, bool) {
+	if s == nil {
+		return decimal.Zero, false
+	}
+
+	// The index of the first change after the day.
+	next := sort.Search(len(s.dates), func(i int) bool { return s.dates[i].After(day) })
+	if next == 0 {
+		return decimal.Zero, false
+	}
+	return s.rates[next-1], true
+}
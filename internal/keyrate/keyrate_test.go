@@ -0,0 +1,68 @@
+package keyrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRate(t *testing.T) {
+	schedule, err := New([]Change{
+		{Date: "2025-06-09", Rate: decimal.NewFromInt(20)},
+		{Date: "2024-10-28", Rate: decimal.NewFromInt(21)},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		day      time.Time
+		expected string
+		ok       bool
+	}{
+		{time.Date(2024, time.October, 27, 0, 0, 0, 0, time.UTC), "0", false},
+		{time.Date(2024, time.October, 28, 0, 0, 0, 0, time.UTC), "21", true},
+		{time.Date(2025, time.June, 8, 0, 0, 0, 0, time.UTC), "21", true},
+		{time.Date(2025, time.June, 9, 0, 0, 0, 0, time.UTC), "20", true},
+		{time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC), "20", true},
+	}
+	for _, tt := range tests {
+		rate, ok := schedule.Rate(tt.day)
+		assert.Equal(t, tt.ok, ok, tt.day)
+		assert.Equal(t, tt.expected, rate.String(), tt.day)
+	}
+
+	var empty *Schedule
+	_, ok := empty.Rate(time.Now())
+	assert.False(t, ok)
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := map[string][]Change{
+		"no rates":       nil,
+		"malformed date": {{Date: "28.10.2024", Rate: decimal.NewFromInt(21)}},
+		"duplicate date": {{Date: "2024-10-28", Rate: decimal.NewFromInt(21)}, {Date: "2024-10-28", Rate: decimal.NewFromInt(20)}},
+		"negative rate":  {{Date: "2024-10-28", Rate: decimal.NewFromInt(-1)}},
+	}
+	for name, changes := range tests {
+		_, err := New(changes)
+		assert.ErrorIs(t, err, ErrInvalidSchedule, name)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key_rate.yml")
+	require.NoError(t, os.WriteFile(path, []byte("rates:\n  - date: 2025-07-28\n    rate: 18\n"), 0o600))
+
+	schedule, err := Load(path)
+	require.NoError(t, err)
+	rate, ok := schedule.Rate(time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, "18", rate.String())
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,84 @@
+// Package logging configures the structured logger and carries the request identifier in the context.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Output formats of the logger.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// RequestIDKey is the attribute with the request identifier added to the records logged with the request context.
+const RequestIDKey = "request_id"
+
+// Errors for logger configuration.
+var (
+	ErrUnknownFormat = errors.New("unknown log format, use text or json")
+	ErrUnknownLevel  = errors.New("unknown log level, use debug, info, warn or error")
+)
+
+type requestIDContextKey struct{}
+
+// New creates the logger writing records of the level and above in the format to w.
+// The empty format and level mean text and info. The records logged with a context holding
+// the request identifier get the request_id attribute.
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var minLevel slog.Level
+	if level != "" {
+		if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownLevel, level)
+		}
+	}
+
+	options := &slog.HandlerOptions{Level: minLevel}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		handler = slog.NewTextHandler(w, options)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+// WithRequestID returns the context carrying the request identifier.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestID returns the request identifier of the context, or an empty string if none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// contextHandler adds the request identifier of the context to the records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
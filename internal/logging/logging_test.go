@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_JSON(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := New(&buffer, "json", "info")
+	require.NoError(t, err)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	logger.DebugContext(ctx, "hidden")
+	logger.With("component", "test").InfoContext(ctx, "Calculation succeeded", "loan_id", 7)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "Calculation succeeded", record["msg"])
+	assert.Equal(t, "req-1", record[RequestIDKey])
+	assert.Equal(t, "test", record["component"])
+	assert.Equal(t, 7.0, record["loan_id"])
+}
+
+func TestNew_Text(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := New(&buffer, "", "")
+	require.NoError(t, err)
+
+	logger.Debug("hidden")
+	logger.InfoContext(context.Background(), "Cache cleared")
+	logger.WarnContext(WithRequestID(context.Background(), "abc"), "Slow request")
+
+	assert.NotContains(t, buffer.String(), "hidden")
+	assert.Contains(t, buffer.String(), `level=INFO msg="Cache cleared"`+"\n")
+	assert.Contains(t, buffer.String(), `level=WARN msg="Slow request" request_id=abc`)
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "xml", "info")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = New(&bytes.Buffer{}, "json", "verbose")
+	assert.ErrorIs(t, err, ErrUnknownLevel)
+
+	logger, err := New(&bytes.Buffer{}, "JSON", "WARN")
+	require.NoError(t, err)
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelWarn))
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Empty(t, RequestID(context.Background()))
+	assert.Equal(t, "id", RequestID(WithRequestID(context.Background(), "id")))
+}
@@ -0,0 +1,258 @@
+// Package metrics implements counters, histograms and gauges exposed in the Prometheus text format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds of the latency histograms in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// collector is a metric family written by the exposition.
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []collector
+)
+
+// register adds the metric to the exposition, in the order of registration.
+func register(metric collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, metric)
+}
+
+// WriteText writes all registered metrics in the Prometheus text exposition format.
+func WriteText(w io.Writer) error {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	buffer := bufio.NewWriter(w)
+	for _, metric := range registry {
+		metric.write(buffer)
+	}
+	return buffer.Flush()
+}
+
+// family holds the description of a metric and its label names.
+type family struct {
+	name, help, kind string
+	labels           []string
+}
+
+func (f family) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.kind)
+}
+
+// series formats the labels with their values, with the extra label appended if given.
+func (f family) series(values []string, extra ...string) string {
+	pairs := make([]string, 0, len(values)+1)
+	for i, value := range values {
+		pairs = append(pairs, f.labels[i]+`="`+escapeLabel(value)+`"`)
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, extra[0]+`="`+escapeLabel(extra[1])+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// key joins the label values into a map key.
+func (f family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// Counter is a monotonically increasing value per combination of label values.
+type Counter struct {
+	family
+	mu          sync.Mutex
+	values      map[string]float64
+	labelValues map[string][]string
+}
+
+// NewCounter creates and registers the counter with the label names.
+func NewCounter(name, help string, labels ...string) *Counter {
+	counter := &Counter{
+		family:      family{name: name, help: help, kind: "counter", labels: labels},
+		values:      make(map[string]float64),
+		labelValues: make(map[string][]string),
+	}
+	register(counter)
+	return counter
+}
+
+// Inc adds one to the counter of the label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds the delta, which must not be negative, to the counter of the label values.
+func (c *Counter) Add(delta float64, values ...string) {
+	key := c.key(values)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.labelValues[key]; !ok {
+		c.labelValues[key] = append([]string(nil), values...)
+	}
+	c.values[key] += delta
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writeHeader(w)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.series(c.labelValues[key]), formatValue(c.values[key]))
+	}
+}
+
+// Histogram counts the observed values in buckets per combination of label values.
+type Histogram struct {
+	family
+	buckets []float64
+	mu      sync.Mutex
+	data    map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64 // Observations per bucket, not cumulative; the last one is +Inf.
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram creates and registers the histogram with the sorted bucket upper bounds and the label names.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	histogram := &Histogram{
+		family:  family{name: name, help: help, kind: "histogram", labels: labels},
+		buckets: append([]float64(nil), buckets...),
+		data:    make(map[string]*histogramSeries),
+	}
+	register(histogram)
+	return histogram
+}
+
+// Observe adds the value to the histogram of the label values.
+func (h *Histogram) Observe(value float64, values ...string) {
+	key := h.key(values)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	series, ok := h.data[key]
+	if !ok {
+		series = &histogramSeries{labels: append([]string(nil), values...), counts: make([]uint64, len(h.buckets)+1)}
+		h.data[key] = series
+	}
+	series.counts[sort.SearchFloat64s(h.buckets, value)]++
+	series.sum += value
+	series.count++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.writeHeader(w)
+	for _, key := range sortedKeys(h.data) {
+		series := h.data[key]
+		cumulative := uint64(0)
+		for i, count := range series.counts {
+			cumulative += count
+			bound := math.Inf(1)
+			if i < len(h.buckets) {
+				bound = h.buckets[i]
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.series(series.labels, "le", formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.series(series.labels), formatValue(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.series(series.labels), series.count)
+	}
+}
+
+// Sample is a value with its label values collected at the exposition time.
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// Func exposes the values computed by the collect function at the exposition time,
+// for values kept elsewhere such as the cache counters.
+type Func struct {
+	family
+	collect func() []Sample
+}
+
+// NewGaugeFunc creates and registers the gauge collected by the function.
+func NewGaugeFunc(name, help string, labels []string, collect func() []Sample) *Func {
+	return newFunc(family{name: name, help: help, kind: "gauge", labels: labels}, collect)
+}
+
+// NewCounterFunc creates and registers the counter collected by the function.
+func NewCounterFunc(name, help string, labels []string, collect func() []Sample) *Func {
+	return newFunc(family{name: name, help: help, kind: "counter", labels: labels}, collect)
+}
+
+func newFunc(description family, collect func() []Sample) *Func {
+	metric := &Func{family: description, collect: collect}
+	register(metric)
+	return metric
+}
+
+func (f *Func) write(w *bufio.Writer) {
+	f.writeHeader(w)
+	for _, sample := range f.collect() {
+		fmt.Fprintf(w, "%s%s %s\n", f.name, f.series(sample.Labels), formatValue(sample.Value))
+	}
+}
+
+// formatValue formats the value as in the exposition format, with +Inf, -Inf and NaN for the special values.
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeLabel escapes the backslashes, quotes and line feeds of the label value.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeHelp escapes the backslashes and line feeds of the help text.
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
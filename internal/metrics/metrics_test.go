@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exposition returns the text of the single metric.
+func exposition(t *testing.T, metric collector) string {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer := bufio.NewWriter(&buffer)
+	metric.write(writer)
+	require.NoError(t, writer.Flush())
+	return buffer.String()
+}
+
+func TestCounter(t *testing.T) {
+	counter := NewCounter("test_requests_total", "Requests by path.\nSecond line.", "path", "status")
+	counter.Inc("/b", "200")
+	counter.Inc("/a", "400")
+	counter.Add(2.5, "/a", "400")
+	counter.Inc(`/c"\`+"\n", "500")
+
+	expected := `# HELP test_requests_total Requests by path.\nSecond line.
+# TYPE test_requests_total counter
+test_requests_total{path="/a",status="400"} 3.5
+test_requests_total{path="/b",status="200"} 1
+test_requests_total{path="/c\"\\\n",status="500"} 1
+`
+	assert.Equal(t, expected, exposition(t, counter))
+	assert.Panics(t, func() { counter.Inc("/a") })
+}
+
+func TestHistogram(t *testing.T) {
+	histogram := NewHistogram("test_duration_seconds", "Durations.", []float64{0.1, 1}, "route")
+	histogram.Observe(0.05, "/x")
+	histogram.Observe(0.1, "/x")
+	histogram.Observe(0.5, "/x")
+	histogram.Observe(3, "/x")
+
+	expected := `# HELP test_duration_seconds Durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/x",le="0.1"} 2
+test_duration_seconds_bucket{route="/x",le="1"} 3
+test_duration_seconds_bucket{route="/x",le="+Inf"} 4
+test_duration_seconds_sum{route="/x"} 3.65
+test_duration_seconds_count{route="/x"} 4
+`
+	assert.Equal(t, expected, exposition(t, histogram))
+}
+
+func TestFunc(t *testing.T) {
+	gauge := NewGaugeFunc("test_entries", "Entries.", []string{"cache"}, func() []Sample {
+		return []Sample{{Labels: []string{"a"}, Value: 3}, {Labels: []string{"b"}, Value: 0.25}}
+	})
+	counter := NewCounterFunc("test_total", "Total.", nil, func() []Sample {
+		return []Sample{{Value: 1e6}}
+	})
+
+	assert.Equal(t, "# HELP test_entries Entries.\n# TYPE test_entries gauge\ntest_entries{cache=\"a\"} 3\ntest_entries{cache=\"b\"} 0.25\n",
+		exposition(t, gauge))
+	assert.Equal(t, "# HELP test_total Total.\n# TYPE test_total counter\ntest_total 1e+06\n", exposition(t, counter))
+}
+
+func TestWriteText(t *testing.T) {
+	NewCounter("test_registered_total", "Registered.").Inc()
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteText(&buffer))
+	assert.True(t, strings.Contains(buffer.String(), "# TYPE test_registered_total counter\ntest_registered_total 1\n"))
+}
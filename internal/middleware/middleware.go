@@ -2,24 +2,106 @@
 package middleware
 
 import (
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/gorilla/mux"
+
+	"sbermortgagecalculator/internal/logging"
+	"sbermortgagecalculator/internal/metrics"
+)
+
+// RequestIDHeader carries the correlation identifier of the request and the response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limits the length of the request identifier accepted from the client.
+const maxRequestIDLength = 128
+
+// unmatchedRoute is the route label of the requests served without a matched route.
+const unmatchedRoute = "unmatched"
+
+// Metrics of the served requests.
+var (
+	requestsTotal = metrics.NewCounter("http_requests_total",
+		"Number of HTTP requests by route, method and status code.", "route", "method", "status")
+	requestDuration = metrics.NewHistogram("http_request_duration_seconds",
+		"Duration of HTTP requests in seconds by route, method and status code.", metrics.DefaultBuckets, "route", "method", "status")
 )
 
 // LoggingMiddleware logging.
+// It propagates the X-Request-ID of the request or generates a new one, passes it to the handlers
+// in the request context, logs the status code and duration of the request and counts them in the metrics.
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		begin := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		r = r.WithContext(logging.WithRequestID(r.Context(), id))
+
 		rw := &responseWriter{w, http.StatusOK}
 
 		next.ServeHTTP(rw, r)
 
 		duration := time.Since(begin)
-		log.Printf("status_code: %d, duration: %s\n", rw.statusCode, duration)
+		route, status := routeTemplate(r), strconv.Itoa(rw.statusCode)
+		requestsTotal.Inc(route, r.Method, status)
+		requestDuration.Observe(duration.Seconds(), route, r.Method, status)
+
+		// The message keeps the line required by the specification for the text output.
+		slog.LogAttrs(r.Context(), slog.LevelInfo,
+			fmt.Sprintf("status_code: %d, duration: %d ns", rw.statusCode, duration.Nanoseconds()),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status_code", rw.statusCode),
+			slog.Duration("duration", duration),
+		)
 	})
 }
 
+// routeTemplate returns the path template of the matched route, which keeps the number of metric series bounded.
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return unmatchedRoute
+}
+
+// validRequestID reports whether the identifier from the client is short and consists of safe characters only.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, char := range id {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9':
+		case char == '-' || char == '_' || char == '.' || char == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random 128-bit identifier in hex.
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
@@ -29,3 +111,8 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap returns the original writer so that http.ResponseController can flush streamed responses.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -2,12 +2,20 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/gorilla/mux"
+
+	"sbermortgagecalculator/internal/logging"
+	"sbermortgagecalculator/internal/metrics"
 )
 
 func TestLoggingMiddleware(t *testing.T) {
@@ -44,3 +52,125 @@ func TestLoggingMiddleware(t *testing.T) {
 		t.Fatalf("the log should contain 'duration:', but the contents of the log: '%s'", logOutput)
 	}
 }
+
+func TestLoggingMiddleware_Flush(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("partial"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("the response was expected to be flushed, but received %v", err)
+		}
+	})
+
+	recorder := httptest.NewRecorder()
+	LoggingMiddleware(nextHandler).ServeHTTP(recorder, httptest.NewRequest("GET", "http://sber.com/test", nil))
+
+	if !recorder.Flushed {
+		t.Fatalf("the response was expected to be flushed")
+	}
+}
+
+func TestLoggingMiddleware_RequestID(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := logging.New(&buffer, logging.FormatJSON, "info")
+	if err != nil {
+		t.Fatalf("failed to create the logger: %v", err)
+	}
+	defaultLogger := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(defaultLogger)
+
+	var handlerID string
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerID = logging.RequestID(r.Context())
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	req := httptest.NewRequest("POST", "http://sber.com/execute", nil)
+	req.Header.Set(RequestIDHeader, "client-42")
+	recorder := httptest.NewRecorder()
+	LoggingMiddleware(nextHandler).ServeHTTP(recorder, req)
+
+	if handlerID != "client-42" {
+		t.Fatalf("the handler context was expected to carry 'client-42', but received '%s'", handlerID)
+	}
+	if id := recorder.Header().Get(RequestIDHeader); id != "client-42" {
+		t.Fatalf("the response was expected to carry 'client-42', but received '%s'", id)
+	}
+
+	var record map[string]any
+	if err = json.Unmarshal(buffer.Bytes(), &record); err != nil {
+		t.Fatalf("the log was expected to be a JSON record, but received '%s'", buffer.String())
+	}
+	expected := map[string]any{"request_id": "client-42", "method": "POST", "path": "/execute", "status_code": 400.0}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("the log field '%s' was expected to be %v, but received %v", key, value, record[key])
+		}
+	}
+	if _, ok := record["duration"].(float64); !ok {
+		t.Errorf("the log should contain the duration in nanoseconds, but the record: %v", record)
+	}
+}
+
+func TestLoggingMiddleware_GeneratedRequestID(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, clientID := range []string{"", "bad id\n", strings.Repeat("a", 200)} {
+		req := httptest.NewRequest("GET", "http://sber.com/test", nil)
+		req.Header.Set(RequestIDHeader, clientID)
+		recorder := httptest.NewRecorder()
+		LoggingMiddleware(nextHandler).ServeHTTP(recorder, req)
+
+		id := recorder.Header().Get(RequestIDHeader)
+		if len(id) != 32 || id == clientID {
+			t.Errorf("a new request id was expected for '%s', but received '%s'", clientID, id)
+		}
+	}
+}
+
+// metricValue returns the current value of the metrics series, zero if it is not exposed yet.
+func metricValue(t *testing.T, series string) float64 {
+	t.Helper()
+	var buffer bytes.Buffer
+	if err := metrics.WriteText(&buffer); err != nil {
+		t.Fatalf("failed to write the metrics: %v", err)
+	}
+	for _, line := range strings.Split(buffer.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, series+" "); ok {
+			result, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("failed to parse the metric '%s': %v", line, err)
+			}
+			return result
+		}
+	}
+	return 0
+}
+
+func TestLoggingMiddleware_Metrics(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(LoggingMiddleware)
+	router.HandleFunc("/loans/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+
+	series := []string{
+		`http_requests_total{route="/loans/{id}",method="GET",status="404"}`,
+		`http_request_duration_seconds_count{route="/loans/{id}",method="GET",status="404"}`,
+		`http_request_duration_seconds_bucket{route="/loans/{id}",method="GET",status="404",le="+Inf"}`,
+	}
+	before := make([]float64, len(series))
+	for i, name := range series {
+		before[i] = metricValue(t, name)
+	}
+
+	for _, path := range []string{"/loans/1", "/loans/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://sber.com"+path, nil))
+	}
+
+	for i, name := range series {
+		if delta := metricValue(t, name) - before[i]; delta != 2 {
+			t.Errorf("the metric '%s' should grow by 2, but it grew by %v", name, delta)
+		}
+	}
+}
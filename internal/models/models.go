@@ -1,6 +1,8 @@
 // Package models contains data structures and database interaction logic.
 package models
 
+import "github.com/shopspring/decimal"
+
 // LoanParams stores the user's request parameters.
 type LoanParams struct {
 	ObjectCost     int `json:"object_cost"`     // Cost object.
@@ -10,31 +12,247 @@ type LoanParams struct {
 
 // Program describes the selected loan program.
 type Program struct {
-	Salary   bool `json:"salary,omitempty"`   // Corporate program.
-	Military bool `json:"military,omitempty"` // Military program.
-	Base     bool `json:"base,omitempty"`     // Base program.
+	ID       string `json:"id,omitempty"`       // Identifier of a configured program.
+	Salary   bool   `json:"salary,omitempty"`   // Corporate program.
+	Military bool   `json:"military,omitempty"` // Military program.
+	Base     bool   `json:"base,omitempty"`     // Base program.
 }
 
+// LoanProgram describes the conditions of a loan program loaded from the configuration.
+type LoanProgram struct {
+	ID                string          `yaml:"id" json:"id"`                                   // Program identifier.
+	Name              string          `yaml:"name" json:"name"`                               // Display name.
+	Rate              decimal.Decimal `yaml:"rate" json:"rate"`                               // Annual interest rate in percent after the rate periods.
+	MinInitialPercent decimal.Decimal `yaml:"min_initial_percent" json:"min_initial_percent"` // Minimum initial payment in percent of the object cost.
+	MinMonths         int             `yaml:"min_months" json:"min_months"`                   // Minimum loan term in months.
+	MaxMonths         int             `yaml:"max_months" json:"max_months"`                   // Maximum loan term in months, 0 means unlimited.
+	MinLoanSum        int             `yaml:"min_loan_sum" json:"min_loan_sum"`               // Minimum loan amount.
+	MaxLoanSum        int             `yaml:"max_loan_sum" json:"max_loan_sum"`               // Maximum loan amount, 0 means unlimited.
+
+	RatePeriods   []RatePeriod     `yaml:"rate_periods" json:"rate_periods,omitempty"`       // Fixed rates of the first months of the term.
+	KeyRateSpread *decimal.Decimal `yaml:"key_rate_spread" json:"key_rate_spread,omitempty"` // Spread over the key rate replacing the rate, in percent.
+}
+
+// RatePeriod is a number of consecutive payments with the same fixed annual rate.
+type RatePeriod struct {
+	Months int             `yaml:"months" json:"months"` // Number of payments in the period.
+	Rate   decimal.Decimal `yaml:"rate" json:"rate"`     // Annual interest rate in percent.
+}
+
+// PaymentPeriod describes the payments of the loan at the same annual rate.
+type PaymentPeriod struct {
+	FromPayment    int             `json:"from_payment"`    // Number of the first payment of the period.
+	ToPayment      int             `json:"to_payment"`      // Number of the last payment of the period.
+	Rate           decimal.Decimal `json:"rate"`            // Annual interest rate in percent.
+	MonthlyPayment Money           `json:"monthly_payment"` // First monthly payment of the period.
+}
+
+// PaymentPeriodV1 describes the payments of the loan at the same rate in whole rubles and percent.
+type PaymentPeriodV1 struct {
+	FromPayment    int `json:"from_payment"`    // Number of the first payment of the period.
+	ToPayment      int `json:"to_payment"`      // Number of the last payment of the period.
+	Rate           int `json:"rate"`            // Annual interest rate in whole percent.
+	MonthlyPayment int `json:"monthly_payment"` // First monthly payment of the period.
+}
+
+// Payment schemes.
+const (
+	PaymentTypeAnnuity        = "annuity"        // Equal monthly payments.
+	PaymentTypeDifferentiated = "differentiated" // Constant principal part and declining interest.
+)
+
 // Aggregates describes the results of loan calculations.
 type Aggregates struct {
+	LastPaymentDate string          `json:"last_payment_date"` // Last payment dates.
+	LoanSum         Money           `json:"loan_sum"`          // Credit amount.
+	Overpayment     Money           `json:"overpayment"`       // Overpayment for the entire period.
+	MonthlyPayment  Money           `json:"monthly_payment"`   // Monthly payment (the first one for the differentiated scheme).
+	FirstPayment    Money           `json:"first_payment"`     // First monthly payment.
+	LastPayment     Money           `json:"last_payment"`      // Last monthly payment.
+	MaxPayment      Money           `json:"max_payment"`       // Maximum monthly payment.
+	Rate            decimal.Decimal `json:"rate"`              // Annual interest rate in percent (of the first payment).
+	Periods         []PaymentPeriod `json:"periods,omitempty"` // Payments by rate period for the stepped and floating rates.
+}
+
+// AggregatesV1 describes the results of loan calculations in whole rubles and percent.
+type AggregatesV1 struct {
 	LastPaymentDate string `json:"last_payment_date"` // Last payment dates.
 	LoanSum         int    `json:"loan_sum"`          // Credit amount.
 	Overpayment     int    `json:"overpayment"`       // Overpayment for the entire period.
-	MonthlyPayment  int    `json:"monthly_payment"`   // Monthly payment.
-	Rate            int    `json:"rate"`              // Annual interest rate.
+	MonthlyPayment  int    `json:"monthly_payment"`   // Monthly payment (the first one for the differentiated scheme).
+	FirstPayment    int    `json:"first_payment"`     // First monthly payment.
+	LastPayment     int    `json:"last_payment"`      // Last monthly payment.
+	MaxPayment      int    `json:"max_payment"`       // Maximum monthly payment.
+	Rate            int    `json:"rate"`              // Annual interest rate in whole percent.
+
+	Periods []PaymentPeriodV1 `json:"periods,omitempty"` // Payments by rate period for the stepped and floating rates.
+}
+
+// V1 converts the aggregates to whole rubles and percent with the rounding mode.
+func (a Aggregates) V1(mode RoundingMode) AggregatesV1 {
+	return AggregatesV1{
+		LastPaymentDate: a.LastPaymentDate,
+		LoanSum:         a.LoanSum.Rubles(mode),
+		Overpayment:     a.Overpayment.Rubles(mode),
+		MonthlyPayment:  a.MonthlyPayment.Rubles(mode),
+		FirstPayment:    a.FirstPayment.Rubles(mode),
+		LastPayment:     a.LastPayment.Rubles(mode),
+		MaxPayment:      a.MaxPayment.Rubles(mode),
+		Rate:            int(a.Rate.Round(0).IntPart()),
+		Periods:         periodsV1(a.Periods, mode),
+	}
+}
+
+// loanV1 converts the aggregates of the loan with the term in months and the payment scheme to whole rubles
+// and percent with the rounding mode. The overpayment of an annuity loan is the total of its monthly payments
+// in whole rubles less the loan sum, as the v1 contract defines it, so it agrees with the payments of the response.
+// The differentiated payments change every month and keep the rounded overpayment.
+func (a Aggregates) loanV1(mode RoundingMode, months int, paymentType string) AggregatesV1 {
+	v1 := a.V1(mode)
+	if paymentType == PaymentTypeDifferentiated || months <= 0 {
+		return v1
+	}
+
+	total := v1.MonthlyPayment * months
+	if len(v1.Periods) > 0 {
+		total = 0
+		for _, period := range v1.Periods {
+			total += period.MonthlyPayment * (period.ToPayment - period.FromPayment + 1)
+		}
+	}
+	v1.Overpayment = total - v1.LoanSum
+	return v1
+}
+
+// periodsV1 converts the payment periods to whole rubles and percent, nil for a loan without periods.
+func periodsV1(periods []PaymentPeriod, mode RoundingMode) []PaymentPeriodV1 {
+	if len(periods) == 0 {
+		return nil
+	}
+
+	converted := make([]PaymentPeriodV1, 0, len(periods))
+	for _, period := range periods {
+		converted = append(converted, PaymentPeriodV1{
+			FromPayment:    period.FromPayment,
+			ToPayment:      period.ToPayment,
+			Rate:           int(period.Rate.Round(0).IntPart()),
+			MonthlyPayment: period.MonthlyPayment.Rubles(mode),
+		})
+	}
+	return converted
 }
 
 // LoanRequest is a structure representing a JSON request.
 type LoanRequest struct {
 	LoanParams
-	Program Program `json:"program"`
+	Program     Program   `json:"program"`
+	PaymentType string    `json:"payment_type,omitempty"` // Payment scheme, annuity by default.
+	Borrower    *Borrower `json:"borrower,omitempty"`     // Borrower data for the affordability check.
+	PaymentDay  int       `json:"payment_day,omitempty"`  // Day of the month the payments are due, the issue day by default.
+	IssueDate   string    `json:"issue_date,omitempty"`   // Loan issue date in the YYYY-MM-DD format, today by default.
+}
+
+// Borrower describes the income and debt load of the borrower.
+type Borrower struct {
+	Income      int `json:"income"`                // Net monthly income.
+	Obligations int `json:"obligations,omitempty"` // Monthly payments on existing loans.
+	Dependants  int `json:"dependants,omitempty"`  // Number of dependants.
+}
+
+// FieldError describes an invalid field of the request.
+type FieldError struct {
+	Field   string `json:"field"`   // Path of the field, e.g. borrower.income or prepayments[0].amount.
+	Code    string `json:"code"`    // Stable error code.
+	Message string `json:"message"` // Human-readable description.
+}
+
+// ValidationErrorResponse structure for the response to an invalid request.
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`  // Summary of the error.
+	Errors []FieldError `json:"errors"` // Errors of the fields.
+}
+
+// Affordability verdicts.
+const (
+	VerdictApproved = "approved" // The debt load is within the approval threshold.
+	VerdictReview   = "review"   // The debt load requires a manual review.
+	VerdictRejected = "rejected" // The debt load exceeds the maximum threshold.
+)
+
+// AffordabilityLimits are the debt-to-income thresholds loaded from the configuration.
+type AffordabilityLimits struct {
+	ApprovePDN         decimal.Decimal `yaml:"approve_pdn"`         // Debt-to-income ratio in percent up to which loans are approved.
+	MaxPDN             decimal.Decimal `yaml:"max_pdn"`             // Debt-to-income ratio in percent above which loans are rejected.
+	DependantAllowance int             `yaml:"dependant_allowance"` // Monthly living costs per dependant deducted from the income.
+}
+
+// Affordability describes the debt-to-income (PDN) check of the borrower.
+type Affordability struct {
+	PDN        decimal.Decimal `json:"pdn"`         // Debt-to-income ratio in percent.
+	MaxPayment Money           `json:"max_payment"` // Maximum monthly payment within the maximum threshold.
+	Verdict    string          `json:"verdict"`     // Approved, review or rejected.
+}
+
+// V1 converts the affordability to whole percent and rubles, the maximum payment is rounded down.
+func (a *Affordability) V1() *AffordabilityV1 {
+	if a == nil {
+		return nil
+	}
+	return &AffordabilityV1{
+		PDN:        int(a.PDN.Round(0).IntPart()),
+		MaxPayment: int(a.MaxPayment.Floor().IntPart()),
+		Verdict:    a.Verdict,
+	}
+}
+
+// AffordabilityV1 describes the debt-to-income check in whole rubles and percent.
+type AffordabilityV1 struct {
+	PDN        int    `json:"pdn"`         // Debt-to-income ratio in whole percent.
+	MaxPayment int    `json:"max_payment"` // Maximum monthly payment within the maximum threshold.
+	Verdict    string `json:"verdict"`     // Approved, review or rejected.
+}
+
+// RejectionResponse structure for the response to a loan rejected by the debt-to-income check.
+type RejectionResponse struct {
+	Error         string         `json:"error"`         // Description of the rejection.
+	Affordability *Affordability `json:"affordability"` // Debt-to-income check of the borrower.
+}
+
+// RejectionResponseV1 structure for the response to a rejected loan in whole rubles and percent.
+type RejectionResponseV1 struct {
+	Error         string           `json:"error"`         // Description of the rejection.
+	Affordability *AffordabilityV1 `json:"affordability"` // Debt-to-income check of the borrower.
 }
 
 // CalculationResult combines a query and a calculation result.
 type CalculationResult struct {
-	Aggregates Aggregates `json:"aggregates"`
-	Params     LoanParams `json:"params"`
-	Program    Program    `json:"program"`
+	Aggregates  Aggregates `json:"aggregates"`
+	Params      LoanParams `json:"params"`
+	Program     Program    `json:"program"`
+	PaymentType string     `json:"payment_type,omitempty"` // Payment scheme.
+
+	Affordability *Affordability `json:"affordability,omitempty"` // Debt-to-income check of the borrower.
+}
+
+// V1 converts the result to whole rubles and percent with the rounding mode.
+func (r CalculationResult) V1(mode RoundingMode) CalculationResultV1 {
+	return CalculationResultV1{
+		Aggregates:    r.Aggregates.loanV1(mode, r.Params.Months, r.PaymentType),
+		Params:        r.Params,
+		Program:       r.Program,
+		PaymentType:   r.PaymentType,
+		Affordability: r.Affordability.V1(),
+	}
+}
+
+// CalculationResultV1 combines a query and a calculation result in whole rubles and percent.
+type CalculationResultV1 struct {
+	Aggregates  AggregatesV1 `json:"aggregates"`
+	Params      LoanParams   `json:"params"`
+	Program     Program      `json:"program"`
+	PaymentType string       `json:"payment_type,omitempty"` // Payment scheme.
+
+	Affordability *AffordabilityV1 `json:"affordability,omitempty"` // Debt-to-income check of the borrower.
 }
 
 // LoanResponse structure for the response.
@@ -42,8 +260,353 @@ type LoanResponse struct {
 	Result CalculationResult `json:"result"`
 }
 
+// LoanResponseV1 structure for the response in whole rubles and percent.
+type LoanResponseV1 struct {
+	Result CalculationResultV1 `json:"result"`
+}
+
 // CachedLoan is a structure for storing data in a cache.
 type CachedLoan struct {
 	CalculationResult
 	ID int `json:"id"`
 }
+
+// V1 converts the cached loan to whole rubles and percent with the rounding mode.
+func (l CachedLoan) V1(mode RoundingMode) CachedLoanV1 {
+	return CachedLoanV1{CalculationResultV1: l.CalculationResult.V1(mode), ID: l.ID}
+}
+
+// CachedLoanV1 is a cached loan in whole rubles and percent.
+type CachedLoanV1 struct {
+	CalculationResultV1
+	ID int `json:"id"`
+}
+
+// SchedulePayment describes a single row of the payment schedule.
+type SchedulePayment struct {
+	Number     int             `json:"number"`     // Payment sequence number.
+	Date       string          `json:"date"`       // Payment date.
+	Rate       decimal.Decimal `json:"rate"`       // Annual interest rate of the payment in percent.
+	Payment    Money           `json:"payment"`    // Total monthly payment.
+	Interest   Money           `json:"interest"`   // Interest part of the payment.
+	Principal  Money           `json:"principal"`  // Principal part of the payment.
+	Prepayment Money           `json:"prepayment"` // Early repayment made together with the payment.
+	Balance    Money           `json:"balance"`    // Remaining loan balance after the payment.
+}
+
+// ScheduleResult combines a calculation result and its payment schedule.
+type ScheduleResult struct {
+	CalculationResult
+	Schedule []SchedulePayment `json:"schedule"`
+}
+
+// ScheduleResponse structure for the schedule response.
+type ScheduleResponse struct {
+	Result ScheduleResult `json:"result"`
+}
+
+// V1 converts the result to whole rubles and percent with the rounding mode, the schedule rows keep kopecks.
+func (r ScheduleResult) V1(mode RoundingMode) ScheduleResultV1 {
+	return ScheduleResultV1{CalculationResultV1: r.CalculationResult.V1(mode), Schedule: r.Schedule}
+}
+
+// ScheduleResultV1 combines a calculation result in whole rubles and percent and its payment schedule.
+type ScheduleResultV1 struct {
+	CalculationResultV1
+	Schedule []SchedulePayment `json:"schedule"`
+}
+
+// ScheduleResponseV1 structure for the schedule response in whole rubles and percent.
+type ScheduleResponseV1 struct {
+	Result ScheduleResultV1 `json:"result"`
+}
+
+// Early repayment strategies.
+const (
+	StrategyReduceTerm    = "reduce_term"    // Keep the monthly payment and shorten the loan term.
+	StrategyReducePayment = "reduce_payment" // Keep the loan term and lower the monthly payment.
+)
+
+// Prepayment describes a one-off or recurring early repayment.
+type Prepayment struct {
+	Month    int    `json:"month"`           // Number of the payment together with which the prepayment is made.
+	Amount   int    `json:"amount"`          // Prepayment amount.
+	Strategy string `json:"strategy"`        // Recalculation strategy: reduce_term or reduce_payment.
+	Every    int    `json:"every,omitempty"` // Repeat period in months, 0 for a one-off prepayment.
+}
+
+// EarlyRepaymentRequest is a structure representing a JSON request with prepayments.
+type EarlyRepaymentRequest struct {
+	LoanRequest
+	Prepayments []Prepayment `json:"prepayments"`
+}
+
+// EarlyRepaymentResult compares the loan with prepayments against the baseline calculation.
+type EarlyRepaymentResult struct {
+	Aggregates    Aggregates        `json:"aggregates"`             // Aggregates with prepayments.
+	Baseline      Aggregates        `json:"baseline"`               // Aggregates without prepayments.
+	Params        LoanParams        `json:"params"`                 // Requested loan parameters.
+	Program       Program           `json:"program"`                // Selected program.
+	PaymentType   string            `json:"payment_type,omitempty"` // Payment scheme.
+	Prepayments   []Prepayment      `json:"prepayments"`            // Requested prepayments.
+	Months        int               `json:"months"`                 // Actual number of payments.
+	InterestSaved Money             `json:"interest_saved"`         // Overpayment reduction versus the baseline.
+	Schedule      []SchedulePayment `json:"schedule"`               // Payment schedule with prepayments.
+}
+
+// EarlyRepaymentResponse structure for the early repayment response.
+type EarlyRepaymentResponse struct {
+	Result EarlyRepaymentResult `json:"result"`
+}
+
+// V1 converts the result to whole rubles and percent with the rounding mode, the schedule rows keep kopecks.
+func (r EarlyRepaymentResult) V1(mode RoundingMode) EarlyRepaymentResultV1 {
+	return EarlyRepaymentResultV1{
+		Aggregates:    r.Aggregates.V1(mode),
+		Baseline:      r.Baseline.loanV1(mode, r.Params.Months, r.PaymentType),
+		Params:        r.Params,
+		Program:       r.Program,
+		PaymentType:   r.PaymentType,
+		Prepayments:   r.Prepayments,
+		Months:        r.Months,
+		InterestSaved: r.InterestSaved.Rubles(mode),
+		Schedule:      r.Schedule,
+	}
+}
+
+// EarlyRepaymentResultV1 compares the loan with prepayments against the baseline in whole rubles and percent.
+type EarlyRepaymentResultV1 struct {
+	Aggregates    AggregatesV1      `json:"aggregates"`             // Aggregates with prepayments.
+	Baseline      AggregatesV1      `json:"baseline"`               // Aggregates without prepayments.
+	Params        LoanParams        `json:"params"`                 // Requested loan parameters.
+	Program       Program           `json:"program"`                // Selected program.
+	PaymentType   string            `json:"payment_type,omitempty"` // Payment scheme.
+	Prepayments   []Prepayment      `json:"prepayments"`            // Requested prepayments.
+	Months        int               `json:"months"`                 // Actual number of payments.
+	InterestSaved int               `json:"interest_saved"`         // Overpayment reduction versus the baseline.
+	Schedule      []SchedulePayment `json:"schedule"`               // Payment schedule with prepayments.
+}
+
+// EarlyRepaymentResponseV1 structure for the early repayment response in whole rubles and percent.
+type EarlyRepaymentResponseV1 struct {
+	Result EarlyRepaymentResultV1 `json:"result"`
+}
+
+// ReverseRequest is a structure representing a JSON request of the reverse calculation.
+// Either the loan term is given to solve for the maximum loan sum, or the loan sum to solve for the term.
+type ReverseRequest struct {
+	MonthlyPayment int     `json:"monthly_payment"`        // Target monthly payment.
+	Months         int     `json:"months,omitempty"`       // Loan term in months.
+	LoanSum        int     `json:"loan_sum,omitempty"`     // Loan amount.
+	Program        Program `json:"program"`                // Selected program.
+	PaymentType    string  `json:"payment_type,omitempty"` // Payment scheme, annuity by default.
+	IssueDate      string  `json:"issue_date,omitempty"`   // Loan issue date in the YYYY-MM-DD format, today by default.
+}
+
+// ReverseResult combines the solved loan parameters and their calculation result.
+type ReverseResult struct {
+	Aggregates    Aggregates `json:"aggregates"`             // Aggregates of the solved loan.
+	Params        LoanParams `json:"params"`                 // Implied object cost with the minimum initial payment and the term.
+	Program       Program    `json:"program"`                // Selected program.
+	PaymentType   string     `json:"payment_type,omitempty"` // Payment scheme.
+	TargetPayment int        `json:"target_payment"`         // Requested target monthly payment.
+}
+
+// ReverseResponse structure for the reverse calculation response.
+type ReverseResponse struct {
+	Result ReverseResult `json:"result"`
+}
+
+// V1 converts the result to whole rubles and percent with the rounding mode.
+func (r ReverseResult) V1(mode RoundingMode) ReverseResultV1 {
+	return ReverseResultV1{
+		Aggregates:    r.Aggregates.loanV1(mode, r.Params.Months, r.PaymentType),
+		Params:        r.Params,
+		Program:       r.Program,
+		PaymentType:   r.PaymentType,
+		TargetPayment: r.TargetPayment,
+	}
+}
+
+// ReverseResultV1 combines the solved loan parameters and their calculation result in whole rubles and percent.
+type ReverseResultV1 struct {
+	Aggregates    AggregatesV1 `json:"aggregates"`             // Aggregates of the solved loan.
+	Params        LoanParams   `json:"params"`                 // Implied object cost with the minimum initial payment and the term.
+	Program       Program      `json:"program"`                // Selected program.
+	PaymentType   string       `json:"payment_type,omitempty"` // Payment scheme.
+	TargetPayment int          `json:"target_payment"`         // Requested target monthly payment.
+}
+
+// ReverseResponseV1 structure for the reverse calculation response in whole rubles and percent.
+type ReverseResponseV1 struct {
+	Result ReverseResultV1 `json:"result"`
+}
+
+// CompareRequest is a structure representing a JSON request of the program comparison.
+type CompareRequest struct {
+	LoanParams
+	PaymentType string    `json:"payment_type,omitempty"` // Payment scheme, annuity by default.
+	Borrower    *Borrower `json:"borrower,omitempty"`     // Borrower data for the affordability check.
+}
+
+// ProgramOffer describes the calculation of the loan with one of the eligible programs.
+type ProgramOffer struct {
+	ProgramID           string          `json:"program_id"`              // Program identifier.
+	Name                string          `json:"name"`                    // Program display name.
+	Rate                decimal.Decimal `json:"rate"`                    // Annual interest rate in percent.
+	MonthlyPayment      Money           `json:"monthly_payment"`         // Monthly payment (the first one for the differentiated scheme).
+	Overpayment         Money           `json:"overpayment"`             // Overpayment for the entire period.
+	MonthlyPaymentDelta Money           `json:"monthly_payment_delta"`   // Monthly payment difference versus the cheapest offer.
+	OverpaymentDelta    Money           `json:"overpayment_delta"`       // Overpayment difference versus the cheapest offer.
+	Aggregates          Aggregates      `json:"aggregates"`              // Full calculation result.
+	Affordability       *Affordability  `json:"affordability,omitempty"` // Debt-to-income check of the borrower.
+}
+
+// IneligibleProgram describes a program the loan does not qualify for.
+type IneligibleProgram struct {
+	ProgramID string `json:"program_id"` // Program identifier.
+	Code      string `json:"code"`       // Stable code of the validation error.
+	Reason    string `json:"reason"`     // Validation error of the program conditions.
+}
+
+// CompareResult lists the eligible programs from the cheapest to the most expensive by overpayment.
+type CompareResult struct {
+	Params      LoanParams          `json:"params"`                 // Requested loan parameters.
+	PaymentType string              `json:"payment_type,omitempty"` // Payment scheme.
+	Offers      []ProgramOffer      `json:"offers"`                 // Eligible programs ranked by overpayment.
+	Ineligible  []IneligibleProgram `json:"ineligible,omitempty"`   // Programs the loan does not qualify for.
+}
+
+// CompareResponse structure for the program comparison response.
+type CompareResponse struct {
+	Result CompareResult `json:"result"`
+}
+
+// V1 converts the offer of the loan with the term in months and the payment scheme to whole rubles and percent
+// with the rounding mode. The payment and the overpayment are those of the converted aggregates.
+func (o ProgramOffer) V1(mode RoundingMode, months int, paymentType string) ProgramOfferV1 {
+	aggregates := o.Aggregates.loanV1(mode, months, paymentType)
+	return ProgramOfferV1{
+		ProgramID:           o.ProgramID,
+		Name:                o.Name,
+		Rate:                int(o.Rate.Round(0).IntPart()),
+		MonthlyPayment:      aggregates.MonthlyPayment,
+		Overpayment:         aggregates.Overpayment,
+		MonthlyPaymentDelta: o.MonthlyPaymentDelta.Rubles(mode),
+		OverpaymentDelta:    o.OverpaymentDelta.Rubles(mode),
+		Aggregates:          aggregates,
+		Affordability:       o.Affordability.V1(),
+	}
+}
+
+// ProgramOfferV1 describes the calculation of the loan with one of the eligible programs in whole rubles and percent.
+type ProgramOfferV1 struct {
+	ProgramID           string           `json:"program_id"`              // Program identifier.
+	Name                string           `json:"name"`                    // Program display name.
+	Rate                int              `json:"rate"`                    // Annual interest rate in whole percent.
+	MonthlyPayment      int              `json:"monthly_payment"`         // Monthly payment (the first one for the differentiated scheme).
+	Overpayment         int              `json:"overpayment"`             // Overpayment for the entire period.
+	MonthlyPaymentDelta int              `json:"monthly_payment_delta"`   // Monthly payment difference versus the cheapest offer.
+	OverpaymentDelta    int              `json:"overpayment_delta"`       // Overpayment difference versus the cheapest offer.
+	Aggregates          AggregatesV1     `json:"aggregates"`              // Full calculation result.
+	Affordability       *AffordabilityV1 `json:"affordability,omitempty"` // Debt-to-income check of the borrower.
+}
+
+// V1 converts the result to whole rubles and percent with the rounding mode.
+// The differences are those of the converted offers versus the cheapest one.
+func (r CompareResult) V1(mode RoundingMode) CompareResultV1 {
+	offers := make([]ProgramOfferV1, 0, len(r.Offers))
+	for _, offer := range r.Offers {
+		offers = append(offers, offer.V1(mode, r.Params.Months, r.PaymentType))
+	}
+	for i := range offers {
+		offers[i].MonthlyPaymentDelta = offers[i].MonthlyPayment - offers[0].MonthlyPayment
+		offers[i].OverpaymentDelta = offers[i].Overpayment - offers[0].Overpayment
+	}
+	return CompareResultV1{Params: r.Params, PaymentType: r.PaymentType, Offers: offers, Ineligible: r.Ineligible}
+}
+
+// CompareResultV1 lists the eligible programs in whole rubles and percent.
+type CompareResultV1 struct {
+	Params      LoanParams          `json:"params"`                 // Requested loan parameters.
+	PaymentType string              `json:"payment_type,omitempty"` // Payment scheme.
+	Offers      []ProgramOfferV1    `json:"offers"`                 // Eligible programs ranked by overpayment.
+	Ineligible  []IneligibleProgram `json:"ineligible,omitempty"`   // Programs the loan does not qualify for.
+}
+
+// CompareResponseV1 structure for the program comparison response in whole rubles and percent.
+type CompareResponseV1 struct {
+	Result CompareResultV1 `json:"result"`
+}
+
+// BatchLimits bounds the batch calculations, zero values keep the defaults.
+type BatchLimits struct {
+	MaxSize int `yaml:"max_size"` // Maximum number of requests in a batch.
+	Workers int `yaml:"workers"`  // Number of concurrent calculations.
+}
+
+// BatchItem is the result or the error of a single request of the batch.
+type BatchItem struct {
+	Index  int                `json:"index"`            // Position of the request in the batch.
+	Result *CalculationResult `json:"result,omitempty"` // Calculation result on success.
+	Code   string             `json:"code,omitempty"`   // Stable code of the calculation error on failure.
+	Error  string             `json:"error,omitempty"`  // Calculation error on failure.
+	Errors []FieldError       `json:"errors,omitempty"` // Errors of the fields of an invalid request.
+}
+
+// BatchResponse structure for the batch calculation response.
+type BatchResponse struct {
+	Results []BatchItem `json:"results"` // Items in the order of the requests.
+}
+
+// V1 converts the item to whole rubles and percent with the rounding mode.
+func (i BatchItem) V1(mode RoundingMode) BatchItemV1 {
+	item := BatchItemV1{Index: i.Index, Code: i.Code, Error: i.Error, Errors: i.Errors}
+	if i.Result != nil {
+		result := i.Result.V1(mode)
+		item.Result = &result
+	}
+	return item
+}
+
+// BatchItemV1 is the result in whole rubles and percent or the error of a single request of the batch.
+type BatchItemV1 struct {
+	Index  int                  `json:"index"`            // Position of the request in the batch.
+	Result *CalculationResultV1 `json:"result,omitempty"` // Calculation result on success.
+	Code   string               `json:"code,omitempty"`   // Stable code of the calculation error on failure.
+	Error  string               `json:"error,omitempty"`  // Calculation error on failure.
+	Errors []FieldError         `json:"errors,omitempty"` // Errors of the fields of an invalid request.
+}
+
+// BatchResponseV1 structure for the batch calculation response in whole rubles and percent.
+type BatchResponseV1 struct {
+	Results []BatchItemV1 `json:"results"` // Items in the order of the requests.
+}
+
+// CachedLoansPage is a page of the cached loans matching the query.
+type CachedLoansPage struct {
+	Items      []CachedLoan `json:"items"`                 // Loans of the page.
+	Total      int          `json:"total"`                 // Number of loans matching the filters.
+	NextCursor string       `json:"next_cursor,omitempty"` // Cursor of the next page, empty on the last page.
+}
+
+// V1 converts the page to whole rubles and percent with the rounding mode.
+func (p CachedLoansPage) V1(mode RoundingMode) CachedLoansPageV1 {
+	return CachedLoansPageV1{Items: CachedLoansV1(p.Items, mode), Total: p.Total, NextCursor: p.NextCursor}
+}
+
+// CachedLoansV1 converts the cached loans to whole rubles and percent with the rounding mode.
+func CachedLoansV1(loans []CachedLoan, mode RoundingMode) []CachedLoanV1 {
+	converted := make([]CachedLoanV1, 0, len(loans))
+	for _, loan := range loans {
+		converted = append(converted, loan.V1(mode))
+	}
+	return converted
+}
+
+// CachedLoansPageV1 is a page of the cached loans in whole rubles and percent.
+type CachedLoansPageV1 struct {
+	Items      []CachedLoanV1 `json:"items"`                 // Loans of the page.
+	Total      int            `json:"total"`                 // Number of loans matching the filters.
+	NextCursor string         `json:"next_cursor,omitempty"` // Cursor of the next page, empty on the last page.
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// Rounding modes of the money amounts.
+const (
+	RoundingHalfUp    RoundingMode = "half_up"    // Half away from zero to kopecks.
+	RoundingBankers   RoundingMode = "bankers"    // Half to even to kopecks.
+	RoundingCeilRuble RoundingMode = "ceil_ruble" // Up to whole rubles.
+)
+
+// RoundingMode describes how money amounts are rounded.
+type RoundingMode string
+
+// Valid reports whether the rounding mode is known.
+func (m RoundingMode) Valid() bool {
+	switch m {
+	case RoundingHalfUp, RoundingBankers, RoundingCeilRuble:
+		return true
+	}
+	return false
+}
+
+// Round rounds the amount to kopecks, or to rubles for the ceil-to-ruble mode.
+func (m RoundingMode) Round(amount decimal.Decimal) decimal.Decimal {
+	switch m {
+	case RoundingBankers:
+		return amount.RoundBank(2)
+	case RoundingCeilRuble:
+		return amount.RoundCeil(0)
+	default:
+		return amount.Round(2)
+	}
+}
+
+// RoundRuble rounds the amount to whole rubles.
+func (m RoundingMode) RoundRuble(amount decimal.Decimal) decimal.Decimal {
+	switch m {
+	case RoundingBankers:
+		return amount.RoundBank(0)
+	case RoundingCeilRuble:
+		return amount.RoundCeil(0)
+	default:
+		return amount.Round(0)
+	}
+}
+
+// Money is an amount in rubles, encoded in JSON as a string with two decimal places.
+type Money struct {
+	decimal.Decimal
+}
+
+// NewMoney wraps the amount without rounding it.
+func NewMoney(amount decimal.Decimal) Money {
+	return Money{Decimal: amount}
+}
+
+// MoneyFromInt creates the amount of whole rubles.
+func MoneyFromInt(rubles int64) Money {
+	return Money{Decimal: decimal.NewFromInt(rubles)}
+}
+
+// MarshalJSON encodes the amount as a string with two decimal places.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.StringFixed(2))), nil
+}
+
+// Rubles returns the amount rounded to whole rubles with the rounding mode.
+func (m Money) Rubles(mode RoundingMode) int {
+	return int(mode.RoundRuble(m.Decimal).IntPart())
+}
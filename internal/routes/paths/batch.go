@@ -0,0 +1,74 @@
+// Package paths implements batch path service.
+package paths
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/models"
+	"sbermortgagecalculator/internal/validation"
+)
+
+// ExecuteBatch handler for calculating an array of loan requests concurrently.
+// The calculations are not saved in the cache.
+func ExecuteBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodPost)
+		return
+	}
+
+	legacy, ok := legacyResponse(w, r)
+	if !ok {
+		return
+	}
+
+	var requests []models.LoanRequest
+	if !readJSONBody(w, r, &requests, maxBatchBodySize) {
+		return
+	}
+
+	language := requestLanguage(w, r)
+	items, err := calculator.CalculateBatch(requests, loanValidator(language))
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, calculator.ErrBatchTooLarge) {
+			statusCode = http.StatusRequestEntityTooLarge
+		}
+		writeCalculationError(w, r, "Batch calculation failed", err, statusCode)
+		return
+	}
+
+	failed := 0
+	for i, item := range items {
+		if item.Error != "" {
+			items[i].Error = itemErrorMessage(language, item.Code, item.Error)
+			failed++
+		}
+	}
+
+	if legacy {
+		writeJSONResponse(w, batchResponseV1(items), http.StatusOK)
+	} else {
+		writeJSONResponse(w, models.BatchResponse{Results: items}, http.StatusOK)
+	}
+	slog.InfoContext(r.Context(), "Batch calculated", "requests", len(items), "failed", failed)
+}
+
+// loanValidator returns the validator of the batch requests with the messages in the language.
+func loanValidator(language string) calculator.BatchValidator {
+	return func(request models.LoanRequest) []models.FieldError {
+		return validation.LoanRequest(request, language)
+	}
+}
+
+// batchResponseV1 converts the batch items to whole rubles and percent with the configured rounding.
+func batchResponseV1(items []models.BatchItem) models.BatchResponseV1 {
+	mode := calculator.Rounding()
+	results := make([]models.BatchItemV1, 0, len(items))
+	for _, item := range items {
+		results = append(results, item.V1(mode))
+	}
+	return models.BatchResponseV1{Results: results}
+}
@@ -2,24 +2,442 @@
 package paths
 
 import (
-	"log"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/export"
+	"sbermortgagecalculator/internal/models"
+	"sbermortgagecalculator/internal/storage"
+)
+
+// Limits of the cache page size.
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
 )
 
+// Fields the cached loans can be sorted by.
+const (
+	sortByID             = "id"
+	sortByMonthlyPayment = "monthly_payment"
+	sortByOverpayment    = "overpayment"
+)
+
+// ErrInvalidQuery is returned for malformed cache query parameters.
+var ErrInvalidQuery = errors.New("invalid query parameter")
+
+// cacheParams are the query parameters of the cache, true for the paging and filtering ones that switch
+// the response from the plain array of loans to the page envelope. Sorting alone keeps the array.
+var cacheParams = map[string]bool{
+	"limit":           true,
+	"offset":          true,
+	"cursor":          true,
+	"program":         true,
+	"min_object_cost": true,
+	"max_object_cost": true,
+	"min_months":      true,
+	"max_months":      true,
+	"rate":            true,
+	"sort":            false,
+	"order":           false,
+}
+
+// cacheQuery describes the paging, filtering and sorting of the cached loans.
+type cacheQuery struct {
+	limit, offset        int
+	after                *pageCursor // Last loan of the previous page, nil for the first page.
+	program              string
+	minCost, maxCost     int
+	minMonths, maxMonths int
+	rate                 decimal.Decimal
+	hasRate              bool // Whether the loans are filtered by the rate, which may be zero.
+	sortBy               string
+	descending           bool
+	paged                bool // Whether the loans are returned as a page envelope.
+}
+
+// pageCursor is the position of the last loan of a page in the sort order it was listed in.
+// It is encoded into an opaque string, so the next page starts after the same loan even if
+// earlier loans are deleted in between.
+type pageCursor struct {
+	sortBy     string
+	descending bool
+	key        decimal.Decimal
+	id         int
+}
+
 // GetCachedLoans handler for getting the cache of all calculations.
+// Without the paging and filtering parameters it returns the plain array of loans, otherwise a page envelope.
+// The Accept header selects the CSV or XLSX export of the same loans.
 func GetCachedLoans(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeJSONError(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodGet)
+		return
+	}
+
+	legacy, ok := legacyResponse(w, r)
+	if !ok {
+		return
+	}
+
+	cachedLoans, err := loanCache.List()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to list cached loans", "error", err)
+		writeJSONError(w, r, "http.read_cache_failed", storeErrorStatus(err))
+		return
+	}
+
+	query, err := parseCacheQuery(r.URL.Query())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Invalid cache query", "error", err)
+		writeJSONError(w, r, "http.invalid_query", http.StatusBadRequest, strings.TrimPrefix(err.Error(), ErrInvalidQuery.Error()+": "))
+		return
+	}
+
+	if query.paged {
+		page := query.apply(cachedLoans)
+		if format := negotiateFormat(r); format != formatJSON {
+			exportLoans(w, r, format, page.Items)
+			return
+		}
+		if legacy {
+			writeJSONResponse(w, page.V1(calculator.Rounding()), http.StatusOK)
+		} else {
+			writeJSONResponse(w, page, http.StatusOK)
+		}
+		slog.InfoContext(r.Context(), "Cached loans returned", "loans", len(page.Items), "total", page.Total)
 		return
 	}
 
-	cachedLoans := getLoansFromSyncMap(&loanCache)
 	if len(cachedLoans) == 0 {
-		log.Println("[INFO] Cache is empty, no loans to retrieve")
-		writeJSONError(w, "empty cache", http.StatusNotFound)
+		slog.InfoContext(r.Context(), "Cache is empty, no loans to retrieve")
+		writeJSONError(w, r, "http.empty_cache", http.StatusNotFound)
+		return
+	}
+
+	query.sortLoans(cachedLoans)
+	if format := negotiateFormat(r); format != formatJSON {
+		exportLoans(w, r, format, cachedLoans)
+		return
+	}
+
+	if legacy {
+		writeJSONResponse(w, models.CachedLoansV1(cachedLoans, calculator.Rounding()), http.StatusOK)
+	} else {
+		writeJSONResponse(w, cachedLoans, http.StatusOK)
+	}
+	slog.InfoContext(r.Context(), "Cached loans returned", "loans", len(cachedLoans))
+}
+
+// exportLoans writes the cached loans as a CSV or XLSX attachment.
+func exportLoans(w http.ResponseWriter, r *http.Request, format string, loans []models.CachedLoan) {
+	writeExport(w, r, format, "loans", func(output io.Writer) error {
+		if format == formatCSV {
+			return export.WriteLoansCSV(output, loans)
+		}
+		return export.WriteLoansXLSX(output, loans)
+	})
+	slog.InfoContext(r.Context(), "Cached loans exported", "loans", len(loans), "format", format)
+}
+
+// GetCachedLoan handler for getting a single calculation from the cache by its identifier.
+func GetCachedLoan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodGet)
+		return
+	}
+
+	legacy, ok := legacyResponse(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := readLoanID(w, r)
+	if !ok {
+		return
+	}
+
+	loan, err := loanCache.Get(id)
+	if err != nil {
+		writeStoreError(w, r, id, err)
+		return
+	}
+
+	if legacy {
+		writeJSONResponse(w, loan.V1(calculator.Rounding()), http.StatusOK)
+	} else {
+		writeJSONResponse(w, loan, http.StatusOK)
+	}
+	slog.InfoContext(r.Context(), "Cached loan returned", "loan_id", id)
+}
+
+// DeleteCachedLoan handler for deleting a single calculation from the cache by its identifier.
+func DeleteCachedLoan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodDelete)
+		return
+	}
+
+	id, ok := readLoanID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := loanCache.Delete(id); err != nil {
+		writeStoreError(w, r, id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.InfoContext(r.Context(), "Cached loan deleted", "loan_id", id)
+}
+
+// ClearCachedLoans handler for deleting all calculations from the cache.
+func ClearCachedLoans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodDelete)
+		return
+	}
+
+	if err := loanCache.Clear(); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to clear cache", "error", err)
+		writeJSONError(w, r, "http.clear_cache_failed", storeErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.InfoContext(r.Context(), "Cache cleared")
+}
+
+// readLoanID parses the loan identifier from the path, writing an error response on failure.
+func readLoanID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 0 {
+		writeJSONError(w, r, "http.invalid_loan_id", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// writeStoreError writes the response for a failed store operation on the loan.
+func writeStoreError(w http.ResponseWriter, r *http.Request, id int, err error) {
+	if errors.Is(err, storage.ErrNotFound) {
+		writeJSONError(w, r, "http.loan_not_found", http.StatusNotFound, id)
 		return
 	}
+	slog.ErrorContext(r.Context(), "Cache operation failed", "loan_id", id, "error", err)
+	writeJSONError(w, r, "http.access_cache_failed", storeErrorStatus(err))
+}
 
-	writeJSONResponse(w, cachedLoans, http.StatusOK)
-	log.Printf("[INFO] Successfully returned %d cached loan(s)", len(cachedLoans))
+// storeErrorStatus returns the status code of the failed store operation: unavailable while the store is loading.
+func storeErrorStatus(err error) int {
+	if errors.Is(err, storage.ErrUnavailable) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// parseCacheQuery reads the paging, filtering and sorting parameters.
+func parseCacheQuery(values url.Values) (cacheQuery, error) {
+	query := cacheQuery{
+		limit:   defaultPageLimit,
+		program: values.Get("program"),
+		sortBy:  sortByID,
+	}
+
+	for name := range values {
+		paged, ok := cacheParams[name]
+		if !ok {
+			return cacheQuery{}, fmt.Errorf("%w: unknown parameter %s", ErrInvalidQuery, name)
+		}
+		query.paged = query.paged || paged
+	}
+
+	intParams := []struct {
+		name   string
+		target *int
+	}{
+		{"limit", &query.limit},
+		{"offset", &query.offset},
+		{"min_object_cost", &query.minCost},
+		{"max_object_cost", &query.maxCost},
+		{"min_months", &query.minMonths},
+		{"max_months", &query.maxMonths},
+	}
+	for _, param := range intParams {
+		raw := values.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return cacheQuery{}, fmt.Errorf("%w: %s", ErrInvalidQuery, param.name)
+		}
+		*param.target = value
+	}
+	if raw := values.Get("rate"); raw != "" {
+		rate, err := decimal.NewFromString(raw)
+		if err != nil || rate.IsNegative() {
+			return cacheQuery{}, fmt.Errorf("%w: rate", ErrInvalidQuery)
+		}
+		query.rate, query.hasRate = rate, true
+	}
+	if query.limit == 0 || query.limit > maxPageLimit {
+		return cacheQuery{}, fmt.Errorf("%w: limit should be between 1 and %d", ErrInvalidQuery, maxPageLimit)
+	}
+
+	if sortBy := values.Get("sort"); sortBy != "" {
+		switch sortBy {
+		case sortByID, sortByMonthlyPayment, sortByOverpayment:
+			query.sortBy = sortBy
+		default:
+			return cacheQuery{}, fmt.Errorf("%w: sort", ErrInvalidQuery)
+		}
+	}
+
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.descending = true
+	default:
+		return cacheQuery{}, fmt.Errorf("%w: order", ErrInvalidQuery)
+	}
+
+	if raw := values.Get("cursor"); raw != "" {
+		if values.Has("offset") {
+			return cacheQuery{}, fmt.Errorf("%w: offset and cursor cannot be combined", ErrInvalidQuery)
+		}
+		after, err := decodeCursor(raw)
+		if err != nil || after.sortBy != query.sortBy || after.descending != query.descending {
+			return cacheQuery{}, fmt.Errorf("%w: cursor", ErrInvalidQuery)
+		}
+		query.after = &after
+	}
+
+	return query, nil
+}
+
+// encodeCursor returns the opaque cursor of the position after the loan.
+func (q cacheQuery) encodeCursor(loan models.CachedLoan) string {
+	order := "asc"
+	if q.descending {
+		order = "desc"
+	}
+	raw := strings.Join([]string{q.sortBy, order, q.sortKey(loan).String(), strconv.Itoa(loan.ID)}, ":")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses the cursor made by encodeCursor.
+func decodeCursor(cursor string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pageCursor{}, err
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 4 || (parts[1] != "asc" && parts[1] != "desc") {
+		return pageCursor{}, ErrInvalidQuery
+	}
+
+	key, err := decimal.NewFromString(parts[2])
+	if err != nil {
+		return pageCursor{}, err
+	}
+	id, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return pageCursor{}, err
+	}
+	return pageCursor{sortBy: parts[0], descending: parts[1] == "desc", key: key, id: id}, nil
+}
+
+// isAfter reports whether the loan follows the cursor in the sort order, the loans with equal keys
+// are ordered by identifier.
+func (q cacheQuery) isAfter(loan models.CachedLoan) bool {
+	order := q.sortKey(loan).Cmp(q.after.key)
+	if q.descending {
+		order = -order
+	}
+	return order > 0 || (order == 0 && loan.ID > q.after.id)
+}
+
+// apply filters, sorts and pages the loans, which must be ordered by identifier.
+func (q cacheQuery) apply(loans []models.CachedLoan) models.CachedLoansPage {
+	matched := make([]models.CachedLoan, 0, len(loans))
+	for _, loan := range loans {
+		if q.matches(loan) {
+			matched = append(matched, loan)
+		}
+	}
+
+	q.sortLoans(matched)
+
+	start := q.offset
+	if q.after != nil {
+		start = sort.Search(len(matched), func(i int) bool { return q.isAfter(matched[i]) })
+	}
+
+	page := models.CachedLoansPage{Items: []models.CachedLoan{}, Total: len(matched)}
+	if start < len(matched) {
+		end := min(start+q.limit, len(matched))
+		page.Items = matched[start:end]
+		if end < len(matched) {
+			page.NextCursor = q.encodeCursor(matched[end-1])
+		}
+	}
+	return page
+}
+
+// sortLoans orders the loans, which must be ordered by identifier, by the sort field.
+// The stable sort keeps loans with equal keys ordered by identifier.
+func (q cacheQuery) sortLoans(loans []models.CachedLoan) {
+	sort.SliceStable(loans, func(i, j int) bool {
+		order := q.sortKey(loans[i]).Cmp(q.sortKey(loans[j]))
+		if q.descending {
+			return order > 0
+		}
+		return order < 0
+	})
+}
+
+// matches reports whether the loan passes the filters.
+func (q cacheQuery) matches(loan models.CachedLoan) bool {
+	params := loan.Params
+	switch {
+	case q.program != "" && calculator.ProgramID(loan.Program) != q.program:
+		return false
+	case q.minCost > 0 && params.ObjectCost < q.minCost:
+		return false
+	case q.maxCost > 0 && params.ObjectCost > q.maxCost:
+		return false
+	case q.minMonths > 0 && params.Months < q.minMonths:
+		return false
+	case q.maxMonths > 0 && params.Months > q.maxMonths:
+		return false
+	case q.hasRate && !loan.Aggregates.Rate.Equal(q.rate):
+		return false
+	}
+	return true
+}
+
+// sortKey returns the value the loan is sorted by.
+func (q cacheQuery) sortKey(loan models.CachedLoan) decimal.Decimal {
+	switch q.sortBy {
+	case sortByMonthlyPayment:
+		return loan.Aggregates.MonthlyPayment.Decimal
+	case sortByOverpayment:
+		return loan.Aggregates.Overpayment.Decimal
+	default:
+		return decimal.NewFromInt(int64(loan.ID))
+	}
 }
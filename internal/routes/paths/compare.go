@@ -0,0 +1,49 @@
+// Package paths implements compare path service.
+package paths
+
+import (
+	"log/slog"
+	"net/http"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/models"
+)
+
+// ExecuteCompare handler for comparing the loan across all eligible programs.
+func ExecuteCompare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodPost)
+		return
+	}
+
+	legacy, ok := legacyResponse(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.CompareRequest
+	if !readJSONRequest(w, r, &request) {
+		return
+	}
+
+	result, err := calculator.ComparePrograms(request)
+	if err != nil {
+		writeCalculationError(w, r, "Program comparison failed", err, http.StatusBadRequest)
+		return
+	}
+
+	language := requestLanguage(w, r)
+	for i := range result.Offers {
+		result.Offers[i].Name = programName(language, result.Offers[i].ProgramID, result.Offers[i].Name)
+	}
+	for i, program := range result.Ineligible {
+		result.Ineligible[i].Reason = itemErrorMessage(language, program.Code, program.Reason)
+	}
+
+	if legacy {
+		writeJSONResponse(w, models.CompareResponseV1{Result: result.V1(calculator.Rounding())}, http.StatusOK)
+	} else {
+		writeJSONResponse(w, models.CompareResponse{Result: result}, http.StatusOK)
+	}
+	slog.InfoContext(r.Context(), "Programs compared", "eligible", len(result.Offers), "cheapest", result.Offers[0].ProgramID)
+}
@@ -0,0 +1,41 @@
+// Package paths implements early repayment path service.
+package paths
+
+import (
+	"log/slog"
+	"net/http"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/models"
+)
+
+// ExecuteEarlyRepayment handler for simulating early repayments of the mortgage.
+func ExecuteEarlyRepayment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodPost)
+		return
+	}
+
+	legacy, ok := legacyResponse(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.EarlyRepaymentRequest
+	if !readJSONRequest(w, r, &request) {
+		return
+	}
+
+	result, err := calculator.CalculateEarlyRepayment(request)
+	if err != nil {
+		writeCalculationError(w, r, "Early repayment calculation failed", err, http.StatusBadRequest)
+		return
+	}
+
+	if legacy {
+		writeJSONResponse(w, models.EarlyRepaymentResponseV1{Result: result.V1(calculator.Rounding())}, http.StatusOK)
+	} else {
+		writeJSONResponse(w, models.EarlyRepaymentResponse{Result: result}, http.StatusOK)
+	}
+	slog.InfoContext(r.Context(), "Early repayment calculated", "interest_saved", result.InterestSaved.StringFixed(2))
+}
@@ -2,66 +2,96 @@
 package paths
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"sync/atomic"
 
 	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/export"
+	"sbermortgagecalculator/internal/i18n"
 	"sbermortgagecalculator/internal/models"
 )
 
-var requestIDCounter int64 = -1
-
 // ExecuteLoanCalculation handler for mortgage calculation.
 func ExecuteLoanCalculation(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeJSONError(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodPost)
 		return
 	}
 
-	var request models.LoanRequest
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		log.Printf("[ERROR] Failed to read request body: %v", err)
-		writeJSONError(w, "Failed to read request body", http.StatusBadRequest)
+	legacy, ok := legacyResponse(w, r)
+	if !ok {
+		return
+	}
+
+	request, ok := readLoanRequest(w, r)
+	if !ok {
 		return
 	}
-	defer func() {
-		if err = r.Body.Close(); err != nil {
-			log.Printf("Error closing body: %v", err)
-		}
-	}()
 
-	if err = json.Unmarshal(body, &request); err != nil {
-		log.Printf("[ERROR] Invalid JSON format: %v", err)
-		writeJSONError(w, "Invalid JSON format", http.StatusBadRequest)
+	result, err := calculator.CalculateLoan(request)
+	if errors.Is(err, calculator.ErrDebtLoadTooHigh) {
+		writeRejection(w, r, legacy, err, result.Affordability)
+		return
+	}
+	if err != nil {
+		writeCalculationError(w, r, "Mortgage calculation failed", err, http.StatusBadRequest)
 		return
 	}
 
-	aggregates, err := calculator.CalculateMortgageAggregates(request)
+	response := models.LoanResponse{Result: result}
+
+	loan, err := loanCache.Save(response.Result)
 	if err != nil {
-		log.Printf("[ERROR] Mortgage calculation failed: %v", err)
-		writeJSONError(w, fmt.Sprintf("Calculation error: %s", err.Error()), http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Failed to save calculation", "error", err)
+		writeJSONError(w, r, "http.save_failed", storeErrorStatus(err))
 		return
 	}
 
-	response := models.LoanResponse{
-		Result: models.CalculationResult{
-			Aggregates: aggregates,
-			Params:     request.LoanParams,
-			Program:    request.Program,
-		},
+	if format := negotiateFormat(r); format != formatJSON {
+		exportCalculation(w, r, format, request, loan)
+		return
 	}
 
-	requestID := atomic.AddInt64(&requestIDCounter, 1)
-	loanCache.Store(requestID, models.CachedLoan{
-		ID:                int(requestID),
-		CalculationResult: response.Result,
-	})
+	if legacy {
+		writeJSONResponse(w, models.LoanResponseV1{Result: response.Result.V1(calculator.Rounding())}, http.StatusOK)
+	} else {
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+	slog.InfoContext(r.Context(), "Calculation succeeded", "loan_id", loan.ID)
+}
+
+// writeRejection writes the error of the loan rejected by the debt-to-income check together with the check,
+// so the client gets the debt load and the maximum payment.
+func writeRejection(w http.ResponseWriter, r *http.Request, legacy bool, err error, affordability *models.Affordability) {
+	slog.InfoContext(r.Context(), "Loan rejected by the affordability check", "error", err)
+	calculationErrors.Inc(calculator.ErrorCode(err))
+
+	language := requestLanguage(w, r)
+	message := i18n.Message(language, "http.calculation_error", calculationMessage(language, err))
+	if legacy {
+		writeJSONResponse(w, models.RejectionResponseV1{Error: message, Affordability: affordability.V1()}, http.StatusUnprocessableEntity)
+	} else {
+		writeJSONResponse(w, models.RejectionResponse{Error: message, Affordability: affordability}, http.StatusUnprocessableEntity)
+	}
+}
 
-	writeJSONResponse(w, response, http.StatusOK)
-	log.Printf("[INFO] Calculation succeeded for Request ID: %d", requestID)
+// exportCalculation writes the calculation with its payment schedule as a CSV or XLSX attachment.
+func exportCalculation(w http.ResponseWriter, r *http.Request, format string, request models.LoanRequest, loan models.CachedLoan) {
+	schedule, err := calculator.CalculatePaymentSchedule(request)
+	if err != nil {
+		writeCalculationError(w, r, "Schedule calculation failed", err, http.StatusBadRequest)
+		return
+	}
+
+	programs := localizedPrograms(requestLanguage(w, r))
+	writeExport(w, r, format, fmt.Sprintf("calculation-%d", loan.ID), func(output io.Writer) error {
+		if format == formatCSV {
+			return export.WriteCalculationCSV(output, loan.CalculationResult, schedule)
+		}
+		return export.WriteCalculationXLSX(output, loan.CalculationResult, schedule, programs)
+	})
+	slog.InfoContext(r.Context(), "Calculation exported", "loan_id", loan.ID, "format", format)
 }
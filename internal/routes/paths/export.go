@@ -0,0 +1,86 @@
+// Package paths implements export of calculations as spreadsheets and documents.
+package paths
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"sbermortgagecalculator/internal/export"
+)
+
+// Response formats selected by the Accept header.
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+	formatPDF  = "pdf"
+)
+
+// exportContentTypes are the content types of the exported documents.
+var exportContentTypes = map[string]string{
+	formatCSV:  export.ContentTypeCSV + "; charset=utf-8",
+	formatXLSX: export.ContentTypeXLSX,
+	formatPDF:  export.ContentTypePDF,
+}
+
+// formatContentTypes maps the media types of the Accept header to the response formats.
+var formatContentTypes = map[string]string{
+	"application/json":     formatJSON,
+	"application/*":        formatJSON,
+	"*/*":                  formatJSON,
+	export.ContentTypeCSV:  formatCSV,
+	"text/*":               formatCSV,
+	export.ContentTypeXLSX: formatXLSX,
+}
+
+// negotiateFormat selects the response format with the highest quality in the Accept header.
+// JSON is returned when the header is missing or lists no supported media type.
+func negotiateFormat(r *http.Request) string {
+	format, quality := formatJSON, 0.0
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		candidate, ok := formatContentTypes[mediaType]
+		if !ok {
+			continue
+		}
+
+		weight := 1.0
+		if raw, found := params["q"]; found {
+			if weight, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if weight > quality {
+			format, quality = candidate, weight
+		}
+	}
+	return format
+}
+
+// writeExport writes the spreadsheet produced by write as an attachment named after the base name.
+// The document is rendered to memory first, so a failure still gets a JSON error response.
+func writeExport(w http.ResponseWriter, r *http.Request, format, name string, write func(w io.Writer) error) {
+	var buffer bytes.Buffer
+	if err := write(&buffer); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to export", "format", format, "error", err)
+		writeJSONError(w, r, "http.export_failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := buffer.WriteTo(w); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write export", "format", format, "error", err)
+	}
+}
@@ -0,0 +1,54 @@
+// Package paths implements health path service.
+package paths
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Statuses of the health and readiness probes.
+const (
+	statusOK       = "ok"
+	statusLoading  = "loading"
+	statusDraining = "draining"
+)
+
+// draining is set when the server stops taking new requests on shutdown.
+var draining atomic.Bool
+
+// SetDraining marks the service as shutting down, so the readiness probe fails while the requests in flight complete.
+func SetDraining(value bool) {
+	draining.Store(value)
+}
+
+// GetHealth handler for the liveness probe, which succeeds while the process serves requests.
+func GetHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodGet)
+		return
+	}
+
+	writeJSONResponse(w, map[string]string{"status": statusOK}, http.StatusOK)
+}
+
+// GetReadiness handler for the readiness probe, which fails while the store is loading or the server is draining.
+func GetReadiness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodGet)
+		return
+	}
+
+	status := statusOK
+	if loader, ok := loanCache.(interface{ Ready() bool }); ok && !loader.Ready() {
+		status = statusLoading
+	}
+	if draining.Load() {
+		status = statusDraining
+	}
+
+	statusCode := http.StatusOK
+	if status != statusOK {
+		statusCode = http.StatusServiceUnavailable
+	}
+	writeJSONResponse(w, map[string]string{"status": status}, statusCode)
+}
@@ -0,0 +1,94 @@
+// Package paths implements metrics path service.
+package paths
+
+import (
+	"log/slog"
+	"net/http"
+
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/i18n"
+	"sbermortgagecalculator/internal/metrics"
+)
+
+// Metrics of the calculations and caches.
+var (
+	calculationErrors = metrics.NewCounter("mortgage_calculation_errors_total",
+		"Number of failed calculations by error code.", "error")
+
+	_ = metrics.NewGaugeFunc("mortgage_cache_entries", "Current number of cache entries.",
+		[]string{"cache"}, cacheSamples(func(stats cache.Stats) float64 { return float64(stats.Size) }))
+	_ = metrics.NewCounterFunc("mortgage_cache_hits_total", "Number of cache lookups that found an entry.",
+		[]string{"cache"}, cacheSamples(func(stats cache.Stats) float64 { return float64(stats.Hits) }))
+	_ = metrics.NewCounterFunc("mortgage_cache_misses_total", "Number of cache lookups that found nothing.",
+		[]string{"cache"}, cacheSamples(func(stats cache.Stats) float64 { return float64(stats.Misses) }))
+	_ = metrics.NewCounterFunc("mortgage_cache_evictions_total", "Number of cache entries evicted by the size limit or TTL.",
+		[]string{"cache"}, cacheSamples(func(stats cache.Stats) float64 { return float64(stats.Evictions) }))
+	_ = metrics.NewGaugeFunc("mortgage_cache_hit_ratio", "Share of cache lookups that found an entry since the start.",
+		[]string{"cache"}, cacheSamples(hitRatio))
+)
+
+// GetMetrics handler for exposing the service metrics in the Prometheus text format.
+func GetMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodGet)
+		return
+	}
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	if err := metrics.WriteText(w); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write metrics", "error", err)
+	}
+}
+
+// writeCalculationError logs the failed calculation, counts it by the error code and writes the error response.
+func writeCalculationError(w http.ResponseWriter, r *http.Request, message string, err error, statusCode int) {
+	slog.ErrorContext(r.Context(), message, "error", err)
+	calculationErrors.Inc(calculator.ErrorCode(err))
+	writeJSONError(w, r, "http.calculation_error", statusCode, calculationMessage(requestLanguage(w, r), err))
+}
+
+// itemErrorMessage counts the failed item of a batch, stream or comparison by its error code
+// and returns its message in the language.
+func itemErrorMessage(language, code, text string) string {
+	calculationErrors.Inc(code)
+	return codeMessage(language, code, text)
+}
+
+// calculationMessage returns the message of the calculation error in the language with its details.
+// The error text is used as is for the languages without the message of the error code.
+func calculationMessage(language string, err error) string {
+	return codeMessage(language, calculator.ErrorCode(err), err.Error())
+}
+
+// codeMessage returns the message of the calculation error code in the language with the details of the error text.
+// The error text is used as is for the languages without the message of the error code.
+func codeMessage(language, code, text string) string {
+	message, ok := i18n.Lookup(language, i18n.PrefixError+code)
+	if !ok {
+		return text
+	}
+	if detail := calculator.MessageDetail(code, text); detail != "" {
+		return message + ": " + detail
+	}
+	return message
+}
+
+// cacheSamples returns the collector of the value for the aggregates cache of the calculator and the loans store.
+func cacheSamples(value func(stats cache.Stats) float64) func() []metrics.Sample {
+	return func() []metrics.Sample {
+		return []metrics.Sample{
+			{Labels: []string{"aggregates"}, Value: value(calculator.CacheStats())},
+			{Labels: []string{"loans"}, Value: value(loanCache.Stats())},
+		}
+	}
+}
+
+// hitRatio returns the share of the lookups that found an entry, zero before the first lookup.
+func hitRatio(stats cache.Stats) float64 {
+	lookups := stats.Hits + stats.Misses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(lookups)
+}
@@ -0,0 +1,53 @@
+// Package paths implements offer path service.
+package paths
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/export"
+)
+
+// ExecuteOffer handler for rendering the calculation with its payment schedule as a printable PDF offer.
+// The loan rejected by the affordability check gets the same response as on /execute.
+func ExecuteOffer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodPost)
+		return
+	}
+
+	legacy, ok := legacyResponse(w, r)
+	if !ok {
+		return
+	}
+
+	request, ok := readLoanRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := calculator.CalculateLoan(request)
+	if errors.Is(err, calculator.ErrDebtLoadTooHigh) {
+		writeRejection(w, r, legacy, err, result.Affordability)
+		return
+	}
+	if err != nil {
+		writeCalculationError(w, r, "Mortgage calculation failed", err, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := calculator.CalculatePaymentSchedule(request)
+	if err != nil {
+		writeCalculationError(w, r, "Schedule calculation failed", err, http.StatusBadRequest)
+		return
+	}
+
+	programs := localizedPrograms(requestLanguage(w, r))
+	writeExport(w, r, formatPDF, "offer", func(output io.Writer) error {
+		return export.WriteOfferPDF(output, result, schedule, programs)
+	})
+	slog.InfoContext(r.Context(), "Offer rendered", "payments", len(schedule))
+}
@@ -1,30 +1,126 @@
 package paths
 
 import (
+	"bytes"
 	"encoding/json"
-	"log"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
-	"sync"
 
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/i18n"
+	"sbermortgagecalculator/internal/logging"
+	"sbermortgagecalculator/internal/middleware"
 	"sbermortgagecalculator/internal/models"
+	"sbermortgagecalculator/internal/storage"
+	"sbermortgagecalculator/internal/validation"
 )
 
-var loanCache sync.Map
+// apiVersionHeader selects the response format: 1 for whole rubles and percent, 2 for decimal strings.
+const apiVersionHeader = "X-API-Version"
 
-// getLoansFromSyncMap bypasses sync.Map and returns the CachedLoan slice.
-func getLoansFromSyncMap(m *sync.Map) []models.CachedLoan {
-	var loans []models.CachedLoan
+// Limits of the request body size in bytes.
+const (
+	maxRequestBodySize = 1 << 20  // Single requests.
+	maxBatchBodySize   = 32 << 20 // Batch requests, up to the default batch size of about 3 KB requests.
+)
+
+// errTrailingData is returned for a body with data after the JSON value.
+var errTrailingData = errors.New("unexpected data after the JSON value")
+
+var loanCache storage.Store = storage.NewMemoryStore()
+
+// SetLoanStore replaces the store of the calculated loans.
+func SetLoanStore(store storage.Store) {
+	loanCache = store
+}
+
+// readLoanRequest reads and decodes the loan request from the body, writing an error response on failure.
+func readLoanRequest(w http.ResponseWriter, r *http.Request) (models.LoanRequest, bool) {
+	var request models.LoanRequest
+	ok := readJSONRequest(w, r, &request)
+	return request, ok
+}
+
+// readJSONRequest reads and decodes the JSON body of at most maxRequestBodySize bytes into request
+// and validates its fields, writing an error response on failure.
+func readJSONRequest(w http.ResponseWriter, r *http.Request, request any) bool {
+	return readJSONBody(w, r, request, maxRequestBodySize)
+}
 
-	m.Range(func(_, value any) bool {
-		if loan, ok := value.(models.CachedLoan); ok {
-			loans = append(loans, loan)
-		} else {
-			log.Printf("Warning: unexpected type in sync.Map: %T\n", value)
+// readJSONBody reads and decodes the JSON body of at most limit bytes into request and validates its fields,
+// writing an error response on failure. Unknown fields are rejected.
+func readJSONBody(w http.ResponseWriter, r *http.Request, request any, limit int64) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			slog.ErrorContext(r.Context(), "Request body too large", "limit", limit)
+			writeJSONError(w, r, "http.body_too_large", http.StatusRequestEntityTooLarge, limit)
+			return false
 		}
-		return true
-	})
+		slog.ErrorContext(r.Context(), "Failed to read request body", "error", err)
+		writeJSONError(w, r, "http.read_body_failed", http.StatusBadRequest)
+		return false
+	}
+	defer func() {
+		if err = r.Body.Close(); err != nil {
+			slog.ErrorContext(r.Context(), "Error closing body", "error", err)
+		}
+	}()
+
+	language := requestLanguage(w, r)
+	if err = decodeJSON(body, request); err != nil {
+		if fieldErrors := validation.DecodeErrors(err, language); fieldErrors != nil {
+			writeValidationError(w, r, fieldErrors)
+			return false
+		}
+		slog.ErrorContext(r.Context(), "Invalid JSON format", "error", err)
+		writeJSONError(w, r, "http.invalid_json", http.StatusBadRequest)
+		return false
+	}
+
+	if fieldErrors := validation.Validate(request, language); fieldErrors != nil {
+		writeValidationError(w, r, fieldErrors)
+		return false
+	}
 
-	return loans
+	return true
+}
+
+// decodeJSON decodes the single JSON value of data into request, rejecting unknown fields and trailing data.
+func decodeJSON(data []byte, request any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errTrailingData
+	}
+	return nil
+}
+
+// writeValidationError writes the errors of the request fields.
+func writeValidationError(w http.ResponseWriter, r *http.Request, fieldErrors []models.FieldError) {
+	slog.InfoContext(r.Context(), "Request validation failed", "fields", len(fieldErrors), "first_field", fieldErrors[0].Field)
+	message := i18n.Message(requestLanguage(w, r), "http.validation_failed")
+	writeJSONResponse(w, models.ValidationErrorResponse{Error: message, Errors: fieldErrors}, http.StatusBadRequest)
+}
+
+// legacyResponse reports whether the client requested the version 1 response format, which is the default.
+// It writes an error response and returns false in ok for unsupported versions.
+func legacyResponse(w http.ResponseWriter, r *http.Request) (legacy, ok bool) {
+	switch r.Header.Get(apiVersionHeader) {
+	case "", "1":
+		return true, true
+	case "2":
+		return false, true
+	default:
+		writeJSONError(w, r, "http.unsupported_api_version", http.StatusBadRequest)
+		return false, false
+	}
 }
 
 // writeJSONResponse writes a JSON response with the specified status code.
@@ -33,12 +129,40 @@ func writeJSONResponse(w http.ResponseWriter, data any, statusCode int) {
 	w.WriteHeader(statusCode)
 
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Printf("[ERROR] Failed to write JSON response: %v", err)
+		// The request context is not available here, the middleware copies its identifier to the response.
+		slog.Error("Failed to write JSON response", "error", err, logging.RequestIDKey, w.Header().Get(middleware.RequestIDHeader))
 		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
 	}
 }
 
-// writeJSONError writes a JSON error response with the specified status code.
-func writeJSONError(w http.ResponseWriter, message string, statusCode int) {
+// writeJSONError writes a JSON error response with the specified status code. The message of the key
+// in the catalogue is formatted with the arguments in the language of the request.
+func writeJSONError(w http.ResponseWriter, r *http.Request, key string, statusCode int, args ...any) {
+	message := i18n.Message(requestLanguage(w, r), key, args...)
 	writeJSONResponse(w, map[string]string{"error": message}, statusCode)
 }
+
+// requestLanguage selects the language of the messages by the Accept-Language header of the request
+// and reports it in the Content-Language header of the response.
+func requestLanguage(w http.ResponseWriter, r *http.Request) string {
+	language := i18n.Language(r.Header.Get("Accept-Language"))
+	w.Header().Set("Content-Language", language)
+	return language
+}
+
+// localizedPrograms returns the configured programs with the display names in the language.
+func localizedPrograms(language string) []models.LoanProgram {
+	programs := calculator.Programs()
+	for i := range programs {
+		programs[i].Name = programName(language, programs[i].ID, programs[i].Name)
+	}
+	return programs
+}
+
+// programName returns the display name of the program in the language, the configured name if the catalogue has none.
+func programName(language, id, name string) string {
+	if localized, ok := i18n.Lookup(language, i18n.PrefixProgram+id); ok {
+		return localized
+	}
+	return name
+}
@@ -5,39 +5,20 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"sync"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/i18n"
 	"sbermortgagecalculator/internal/models"
+	"sbermortgagecalculator/internal/storage"
 )
 
-func TestGetLoansFromSyncMap(t *testing.T) {
-	var m sync.Map
-
-	loan1 := models.CachedLoan{ID: 0}
-	loan2 := models.CachedLoan{ID: 1}
-	m.Store(0, loan1)
-	m.Store(1, loan2)
-	m.Store(2, "invalid_type")
-
-	loans := getLoansFromSyncMap(&m)
-
-	// Ожидаемый результат
-	expectedLoans := []models.CachedLoan{loan1, loan2}
-
-	// Проверка длины результата
-	if len(loans) != len(expectedLoans) {
-		t.Errorf("Expected %d loans, but got %d", len(expectedLoans), len(loans))
-	}
-
-	// Проверка содержимого результата
-	for i, loan := range loans {
-		if loan.ID != expectedLoans[i].ID {
-			t.Errorf("Expected loan ID %d, but got %d", expectedLoans[i].ID, loan.ID)
-		}
-	}
-}
-
 func TestWriteJSONResponse(t *testing.T) {
 	recorder := httptest.NewRecorder()
 
@@ -88,7 +69,7 @@ func TestWriteJSONError(t *testing.T) {
 	errorMessage := "test error"
 	statusCode := http.StatusBadRequest
 
-	writeJSONError(recorder, errorMessage, statusCode)
+	writeJSONError(recorder, httptest.NewRequest(http.MethodGet, "/", nil), errorMessage, statusCode)
 
 	if recorder.Code != statusCode {
 		t.Errorf("Expected status code %d, but got %d", statusCode, recorder.Code)
@@ -125,7 +106,7 @@ func TestGetCachedLoans_MethodNotAllowed(t *testing.T) {
 }
 
 func TestGetCachedLoans_EmptyCache(t *testing.T) {
-	loanCache = sync.Map{}
+	SetLoanStore(storage.NewMemoryStore())
 
 	req := httptest.NewRequest(http.MethodGet, "/cache", nil)
 	rec := httptest.NewRecorder()
@@ -143,11 +124,9 @@ func TestGetCachedLoans_EmptyCache(t *testing.T) {
 }
 
 func TestGetCachedLoans_NonEmptyCache(t *testing.T) {
-	loanCache = sync.Map{}
-	loan1 := models.CachedLoan{ID: 0}
-	loan2 := models.CachedLoan{ID: 1}
-	loanCache.Store(loan1.ID, loan1)
-	loanCache.Store(loan2.ID, loan2)
+	SetLoanStore(storage.NewMemoryStore())
+	loan1, _ := loanCache.Save(models.CalculationResult{Params: models.LoanParams{Months: 120}})
+	loan2, _ := loanCache.Save(models.CalculationResult{Params: models.LoanParams{Months: 240}})
 
 	req := httptest.NewRequest(http.MethodGet, "/cache", nil)
 	rec := httptest.NewRecorder()
@@ -158,17 +137,17 @@ func TestGetCachedLoans_NonEmptyCache(t *testing.T) {
 		t.Errorf("Expected status %d, but got %d", http.StatusOK, rec.Code)
 	}
 
-	var loans []models.CachedLoan
+	var loans []models.CachedLoanV1
 	if err := json.Unmarshal(rec.Body.Bytes(), &loans); err != nil {
 		t.Fatalf("Failed to decode JSON response: %v", err)
 	}
 
-	expectedLoans := []models.CachedLoan{loan1, loan2}
+	expectedLoans := models.CachedLoansV1([]models.CachedLoan{loan1, loan2}, models.RoundingHalfUp)
 	if len(loans) != len(expectedLoans) {
 		t.Fatalf("Expected %d loans, but got %d", len(expectedLoans), len(loans))
 	}
 	for i, loan := range loans {
-		if loan != expectedLoans[i] {
+		if !reflect.DeepEqual(loan, expectedLoans[i]) {
 			t.Errorf("Expected loan %v, but got %v", expectedLoans[i], loan)
 		}
 	}
@@ -234,6 +213,120 @@ func TestExecuteLoanCalculation_CalculationError(t *testing.T) {
 	}
 }
 
+func TestExecuteLoanCalculation_Validation(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "negative object cost and absurd term",
+			body:         `{"object_cost":-1,"initial_payment":0,"months":100000,"program":{"salary":true}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"Validation failed","errors":[` +
+				`{"field":"object_cost","code":"not_positive","message":"must be greater than zero"},` +
+				`{"field":"months","code":"too_large","message":"must not exceed 600"}]}`,
+		},
+		{
+			name:         "unknown field",
+			body:         `{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true},"rate":1}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"Validation failed","errors":[{"field":"rate","code":"unknown_field","message":"is not a field of the request"}]}`,
+		},
+		{
+			name:         "invalid type",
+			body:         `{"object_cost":5000000,"initial_payment":1000000,"months":"240","program":{"salary":true}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"Validation failed","errors":[{"field":"months","code":"invalid_type","message":"must be int, got string"}]}`,
+		},
+		{
+			name:         "trailing data",
+			body:         `{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true}} {}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"Invalid JSON format"}`,
+		},
+		{
+			name:         "too large body",
+			body:         `{"payment_type":"` + strings.Repeat("a", maxRequestBodySize) + `"}`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: `{"error":"Request body exceeds 1048576 bytes"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/execute", bytes.NewBufferString(tc.body))
+		rec := httptest.NewRecorder()
+		ExecuteLoanCalculation(rec, req)
+
+		if rec.Code != tc.expectedCode {
+			t.Errorf("%s: expected status %d, but got %d", tc.name, tc.expectedCode, rec.Code)
+		}
+		if rec.Body.String() != tc.expectedBody+"\n" {
+			t.Errorf("%s: expected body %q, but got %q", tc.name, tc.expectedBody, rec.Body.String())
+		}
+	}
+}
+
+func TestExecuteLoanCalculation_Localized(t *testing.T) {
+	tests := []struct {
+		language     string
+		body         string
+		expectedBody string
+	}{
+		{
+			body:         `{"object_cost":5000000,"initial_payment":1000000,"months":240}`,
+			expectedBody: `{"error":"Calculation error: choose program"}`,
+		},
+		{
+			language:     "ru-RU,ru;q=0.9,en;q=0.8",
+			body:         `{"object_cost":5000000,"initial_payment":1000000,"months":240}`,
+			expectedBody: `{"error":"Ошибка расчета: выберите программу"}`,
+		},
+		{
+			language:     "ru",
+			body:         `{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true},"borrower":{"income":40000}}`,
+			expectedBody: `{"error":"Ошибка расчета: показатель долговой нагрузки превышает допустимый: 83.65% with the maximum payment 32000.00","affordability":{"pdn":84,"max_payment":32000,"verdict":"rejected"}}`,
+		},
+		{
+			language:     "ru",
+			body:         `{"object_cost":5000000,"initial_payment":1000000,"months":601,"program":{"salary":true}}`,
+			expectedBody: `{"error":"Ошибка проверки запроса","errors":[{"field":"months","code":"too_large","message":"не должно превышать 600"}]}`,
+		},
+		{
+			language:     "ru",
+			body:         `invalid JSON`,
+			expectedBody: `{"error":"Неверный формат JSON"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/execute", bytes.NewBufferString(tc.body))
+		req.Header.Set("Accept-Language", tc.language)
+		rec := httptest.NewRecorder()
+		ExecuteLoanCalculation(rec, req)
+
+		if rec.Code != http.StatusBadRequest && rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%q: expected an error status, but got %d", tc.language, rec.Code)
+		}
+		if rec.Body.String() != tc.expectedBody+"\n" {
+			t.Errorf("%q: expected body %q, but got %q", tc.language, tc.expectedBody, rec.Body.String())
+		}
+		if expected := i18n.Language(tc.language); rec.Header().Get("Content-Language") != expected {
+			t.Errorf("%q: expected Content-Language %q, but got %q", tc.language, expected, rec.Header().Get("Content-Language"))
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/execute", nil)
+	req.Header.Set("Accept-Language", "ru")
+	rec := httptest.NewRecorder()
+	ExecuteLoanCalculation(rec, req)
+
+	if expected := `{"error":"Разрешен только метод POST"}` + "\n"; rec.Body.String() != expected {
+		t.Errorf("Expected body %q, but got %q", expected, rec.Body.String())
+	}
+}
+
 func TestExecuteLoanCalculation_Success(t *testing.T) {
 	request := models.LoanRequest{
 		LoanParams: models.LoanParams{
@@ -253,7 +346,7 @@ func TestExecuteLoanCalculation_Success(t *testing.T) {
 		t.Errorf("Expected status %d, but got %d", http.StatusOK, rec.Code)
 	}
 
-	var response models.LoanResponse
+	var response models.LoanResponseV1
 	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
 		t.Fatalf("Failed to parse JSON response: %v", err)
 	}
@@ -266,4 +359,991 @@ func TestExecuteLoanCalculation_Success(t *testing.T) {
 		t.Errorf("Expected rate amount 8, but got %d", response.Result.Aggregates.Rate)
 	}
 
+	if response.Result.Aggregates.MonthlyPayment != 33458 {
+		t.Errorf("Expected monthly payment 33458, but got %d", response.Result.Aggregates.MonthlyPayment)
+	}
+
+	// The v1 overpayment is the total of the rounded monthly payments less the loan sum: 33458 * 240 - 4000000.
+	if response.Result.Aggregates.Overpayment != 4029920 {
+		t.Errorf("Expected overpayment 4029920, but got %d", response.Result.Aggregates.Overpayment)
+	}
+
+	loans, _ := loanCache.List()
+	if len(loans) == 0 || !reflect.DeepEqual(loans[len(loans)-1].V1(models.RoundingHalfUp).CalculationResultV1, response.Result) {
+		t.Errorf("Expected the calculation to be saved in the cache")
+	}
+
+}
+
+func TestExecuteLoanCalculation_Versions(t *testing.T) {
+	body := `{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true}}`
+
+	tests := []struct {
+		version      string
+		expectedCode int
+		expectedBody string
+	}{
+		{version: "2", expectedCode: http.StatusOK, expectedBody: `"monthly_payment":"33457.60"`},
+		{version: "1", expectedCode: http.StatusOK, expectedBody: `"monthly_payment":33458`},
+		{version: "3", expectedCode: http.StatusBadRequest, expectedBody: `{"error":"unsupported API version"}`},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/execute", bytes.NewBufferString(body))
+		req.Header.Set(apiVersionHeader, tc.version)
+		rec := httptest.NewRecorder()
+		ExecuteLoanCalculation(rec, req)
+
+		if rec.Code != tc.expectedCode {
+			t.Errorf("v%s: expected status %d, but got %d", tc.version, tc.expectedCode, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), tc.expectedBody) {
+			t.Errorf("v%s: expected body to contain %q, but got %q", tc.version, tc.expectedBody, rec.Body.String())
+		}
+	}
+}
+
+func TestExecuteLoanCalculation_Affordability(t *testing.T) {
+	tests := []struct {
+		borrower     string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			borrower:     `{"income":100000,"obligations":10000}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `"affordability":{"pdn":43,"max_payment":70000,"verdict":"approved"}`,
+		},
+		{
+			borrower:     `{"income":40000}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `"affordability":{"pdn":84,"max_payment":32000,"verdict":"rejected"}`,
+		},
+		{
+			borrower:     `{"income":40000,"dependants":3}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `"affordability":{"pdn":0,"max_payment":0,"verdict":"rejected"}`,
+		},
+		{
+			borrower:     `{"income":0}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"field":"borrower.income","code":"not_positive","message":"must be greater than zero"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		body := `{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true},"borrower":` + tc.borrower + `}`
+		req := httptest.NewRequest(http.MethodPost, "/execute", bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		ExecuteLoanCalculation(rec, req)
+
+		if rec.Code != tc.expectedCode {
+			t.Errorf("%s: expected status %d, but got %d", tc.borrower, tc.expectedCode, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), tc.expectedBody) {
+			t.Errorf("%s: expected body to contain %q, but got %q", tc.borrower, tc.expectedBody, rec.Body.String())
+		}
+	}
+}
+
+func TestExecuteBatch(t *testing.T) {
+	body := `[{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true}},` +
+		`{"object_cost":5000000,"initial_payment":1000000,"months":240}]`
+
+	req := httptest.NewRequest(http.MethodPost, "/execute/batch", bytes.NewBufferString(body))
+	req.Header.Set(apiVersionHeader, "2")
+	rec := httptest.NewRecorder()
+	ExecuteBatch(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+
+	var response models.BatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+
+	if len(response.Results) != 2 {
+		t.Fatalf("Expected 2 results, but got %d", len(response.Results))
+	}
+	if first := response.Results[0]; first.Result == nil || first.Result.Aggregates.MonthlyPayment.StringFixed(2) != "33457.60" {
+		t.Errorf("Expected the first request to be calculated, but got %+v", first)
+	}
+	if second := response.Results[1]; second.Index != 1 || second.Result != nil || second.Code != "no_program_selected" || second.Error != "choose program" {
+		t.Errorf("Expected the second request to fail, but got %+v", second)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/execute/batch", bytes.NewBufferString(body))
+	req.Header.Set("Accept-Language", "ru")
+	rec = httptest.NewRecorder()
+	ExecuteBatch(rec, req)
+
+	if expected := `{"index":1,"code":"no_program_selected","error":"выберите программу"}`; !strings.Contains(rec.Body.String(), expected) {
+		t.Errorf("Expected the error of the second request in Russian, but got %q", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/execute/batch", bytes.NewBufferString("[]"))
+	rec = httptest.NewRecorder()
+	ExecuteBatch(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, but got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestExecuteBatch_Validation(t *testing.T) {
+	body := `[{"object_cost":5000000,"initial_payment":1000000,"months":200000,"program":{"salary":true}},` +
+		`{"object_cost":-5000000,"initial_payment":1000000,"months":240,"program":{"salary":true}},` +
+		`{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true}}]`
+
+	req := httptest.NewRequest(http.MethodPost, "/execute/batch", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	ExecuteBatch(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+
+	var response models.BatchResponseV1
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if len(response.Results) != 3 {
+		t.Fatalf("Expected 3 results, but got %d", len(response.Results))
+	}
+
+	expectedFields := []string{"months", "object_cost"}
+	for i, field := range expectedFields {
+		item := response.Results[i]
+		if item.Result != nil || item.Code != "invalid_request" || len(item.Errors) == 0 || item.Errors[0].Field != field {
+			t.Errorf("Expected request %d to fail the validation of %s, but got %+v", i, field, item)
+		}
+	}
+	if last := response.Results[2]; last.Result == nil || last.Error != "" {
+		t.Errorf("Expected the last request to be calculated, but got %+v", last)
+	}
+}
+
+func TestExecuteStream(t *testing.T) {
+	body := `{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true}}
+
+not a JSON
+{"object_cost":3000000,"initial_payment":600000,"months":180}
+{"object_cost":3000000,"initial_payment":600000,"months":180,"program":{"base":true}}
+`
+
+	req := httptest.NewRequest(http.MethodPost, "/execute/stream", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	ExecuteStream(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+		t.Errorf("Expected NDJSON content type, but got %q", contentType)
+	}
+	if !rec.Flushed {
+		t.Errorf("Expected the results to be flushed")
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	expectedErrors := []string{"", "Invalid JSON format", "choose program", ""}
+	expectedCodes := []string{"", "invalid_json", "no_program_selected", ""}
+	if len(lines) != len(expectedErrors) {
+		t.Fatalf("Expected %d lines, but got %d: %q", len(expectedErrors), len(lines), rec.Body.String())
+	}
+	for i, line := range lines {
+		var item models.BatchItem
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Fatalf("Failed to parse line %d: %v", i, err)
+		}
+		if item.Index != i || item.Error != expectedErrors[i] || item.Code != expectedCodes[i] || (item.Result == nil) == (expectedErrors[i] == "") {
+			t.Errorf("Line %d: unexpected item %+v", i, item)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/execute/stream", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Accept-Language", "ru")
+	rec = httptest.NewRecorder()
+	ExecuteStream(rec, req)
+
+	for _, expected := range []string{
+		`{"index":1,"code":"invalid_json","error":"Неверный формат JSON"}`,
+		`{"index":2,"code":"no_program_selected","error":"выберите программу"}`,
+	} {
+		if !strings.Contains(rec.Body.String(), expected) {
+			t.Errorf("Expected the stream to contain %q, but got %q", expected, rec.Body.String())
+		}
+	}
+}
+
+func TestExecuteStream_Validation(t *testing.T) {
+	body := `{"object_cost":5000000,"initial_payment":1000000,"months":200000,"program":{"salary":true}}
+{"object_cost":5000000,"initial_payment":-1000000,"months":240,"program":{"salary":true}}
+{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true},"rate":8}
+{"object_cost":"5000000","initial_payment":1000000,"months":240,"program":{"salary":true}}
+`
+
+	req := httptest.NewRequest(http.MethodPost, "/execute/stream", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Accept-Language", "ru")
+	rec := httptest.NewRecorder()
+	ExecuteStream(rec, req)
+
+	for _, expected := range []string{
+		`{"index":0,"code":"invalid_request","error":"запрос содержит неверные поля","errors":[{"field":"months","code":"too_large","message":"не должно превышать 600"}]}`,
+		`{"index":1,"code":"invalid_request","error":"запрос содержит неверные поля","errors":[{"field":"initial_payment","code":"negative"`,
+		`{"index":2,"code":"invalid_request","error":"запрос содержит неверные поля","errors":[{"field":"rate","code":"unknown_field"`,
+		`{"index":3,"code":"invalid_request","error":"запрос содержит неверные поля","errors":[{"field":"object_cost","code":"invalid_type"`,
+	} {
+		if !strings.Contains(rec.Body.String(), expected) {
+			t.Errorf("Expected the stream to contain %q, but got %q", expected, rec.Body.String())
+		}
+	}
+}
+
+func TestExecuteStream_UnsupportedMediaType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/execute/stream", bytes.NewBufferString("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	ExecuteStream(rec, req)
+
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status %d, but got %d", http.StatusUnsupportedMediaType, rec.Code)
+	}
+}
+
+func TestExecuteSchedule_MethodNotAllowed(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/schedule", nil)
+	rec := httptest.NewRecorder()
+
+	ExecuteSchedule(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, but got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
+
+func TestExecuteSchedule_Success(t *testing.T) {
+	request := models.LoanRequest{
+		LoanParams: models.LoanParams{
+			ObjectCost:     3000000,
+			InitialPayment: 600000,
+			Months:         12,
+		},
+		Program: models.Program{Base: true},
+	}
+	body, _ := json.Marshal(request)
+
+	req := httptest.NewRequest(http.MethodPost, "/schedule", bytes.NewReader(body))
+	req.Header.Set(apiVersionHeader, "2")
+	rec := httptest.NewRecorder()
+	ExecuteSchedule(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+
+	var response models.ScheduleResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+
+	if len(response.Result.Schedule) != 12 {
+		t.Fatalf("Expected 12 payments, but got %d", len(response.Result.Schedule))
+	}
+
+	if last := response.Result.Schedule[11]; !last.Balance.IsZero() {
+		t.Errorf("Expected zero balance after the last payment, but got %s", last.Balance)
+	}
+}
+
+func TestExecuteSchedule_Versions(t *testing.T) {
+	body := `{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true}}`
+
+	tests := []struct {
+		version      string
+		expectedCode int
+		expectedBody string
+	}{
+		{version: "", expectedCode: http.StatusOK, expectedBody: `"monthly_payment":33458,`},
+		{version: "1", expectedCode: http.StatusOK, expectedBody: `"monthly_payment":33458,`},
+		{version: "2", expectedCode: http.StatusOK, expectedBody: `"monthly_payment":"33457.60"`},
+		{version: "3", expectedCode: http.StatusBadRequest, expectedBody: `{"error":"unsupported API version"}`},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/schedule", bytes.NewBufferString(body))
+		if tc.version != "" {
+			req.Header.Set(apiVersionHeader, tc.version)
+		}
+		rec := httptest.NewRecorder()
+		ExecuteSchedule(rec, req)
+
+		if rec.Code != tc.expectedCode {
+			t.Errorf("v%s: expected status %d, but got %d", tc.version, tc.expectedCode, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), tc.expectedBody) {
+			t.Errorf("v%s: expected body to contain %q, but got %q", tc.version, tc.expectedBody, rec.Body.String())
+		}
+		if tc.expectedCode == http.StatusOK && !strings.Contains(rec.Body.String(), `"schedule":[{"number":1,`) {
+			t.Errorf("v%s: expected the payment schedule, but got %q", tc.version, rec.Body.String())
+		}
+	}
+}
+
+func TestExecuteEarlyRepayment_Success(t *testing.T) {
+	request := models.EarlyRepaymentRequest{
+		LoanRequest: models.LoanRequest{
+			LoanParams: models.LoanParams{
+				ObjectCost:     5000000,
+				InitialPayment: 1000000,
+				Months:         240,
+			},
+			Program: models.Program{Salary: true},
+		},
+		Prepayments: []models.Prepayment{{Month: 36, Amount: 500000, Strategy: models.StrategyReduceTerm}},
+	}
+	body, _ := json.Marshal(request)
+
+	req := httptest.NewRequest(http.MethodPost, "/early-repayment", bytes.NewReader(body))
+	req.Header.Set(apiVersionHeader, "2")
+	rec := httptest.NewRecorder()
+	ExecuteEarlyRepayment(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+
+	var response models.EarlyRepaymentResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+
+	if !response.Result.InterestSaved.IsPositive() {
+		t.Errorf("Expected positive interest saved, but got %s", response.Result.InterestSaved)
+	}
+	if response.Result.Months >= 240 {
+		t.Errorf("Expected shorter term, but got %d months", response.Result.Months)
+	}
+}
+
+func TestExecuteEarlyRepayment_CalculationError(t *testing.T) {
+	body := bytes.NewBufferString(`{"object_cost":5000000,"initial_payment":1000000,"months":240,"program":{"salary":true},"prepayments":[{"month":36,"amount":500000,"strategy":"skip"}]}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/early-repayment", body)
+	rec := httptest.NewRecorder()
+	ExecuteEarlyRepayment(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, but got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestExecuteReverse(t *testing.T) {
+	tests := []struct {
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			body:         `{"monthly_payment":60000,"loan_sum":4000000,"program":{"salary":true}}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `"params":{"object_cost":5000000,"initial_payment":1000000,"months":89}`,
+		},
+		{
+			body:         `{"monthly_payment":60000,"months":240,"loan_sum":4000000,"program":{"salary":true}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"Calculation error: specify either the loan term or the loan sum"}`,
+		},
+		{
+			body:         `{"monthly_payment":60000,"months":240}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"Calculation error: choose program"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/reverse", bytes.NewBufferString(tc.body))
+		rec := httptest.NewRecorder()
+		ExecuteReverse(rec, req)
+
+		if rec.Code != tc.expectedCode {
+			t.Errorf("%s: expected status %d, but got %d", tc.body, tc.expectedCode, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), tc.expectedBody) {
+			t.Errorf("%s: expected body to contain %q, but got %q", tc.body, tc.expectedBody, rec.Body.String())
+		}
+	}
+}
+
+func TestExecuteCompare(t *testing.T) {
+	body := bytes.NewBufferString(`{"object_cost":5000000,"initial_payment":1000000,"months":240}`)
+	req := httptest.NewRequest(http.MethodPost, "/compare", body)
+	req.Header.Set(apiVersionHeader, "2")
+	rec := httptest.NewRecorder()
+	ExecuteCompare(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+
+	var response models.CompareResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+
+	if len(response.Result.Offers) != 3 {
+		t.Fatalf("Expected 3 offers, but got %d", len(response.Result.Offers))
+	}
+	if cheapest := response.Result.Offers[0]; cheapest.ProgramID != "salary" || !cheapest.OverpaymentDelta.IsZero() {
+		t.Errorf("Expected the salary program to be the cheapest, but got %+v", cheapest)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/compare", bytes.NewBufferString(`{"object_cost":5000000,"initial_payment":1000000,"months":240}`))
+	req.Header.Set("Accept-Language", "ru")
+	rec = httptest.NewRecorder()
+	ExecuteCompare(rec, req)
+
+	if !strings.Contains(rec.Body.String(), `"program_id":"military","name":"Военная ипотека"`) {
+		t.Errorf("Expected the program names in Russian, but got %q", rec.Body.String())
+	}
+
+	body = bytes.NewBufferString(`{"object_cost":5000000,"initial_payment":1000000,"months":240,"borrower":{"income":45000}}`)
+	req = httptest.NewRequest(http.MethodPost, "/compare", body)
+	req.Header.Set("Accept-Language", "ru")
+	rec = httptest.NewRecorder()
+	ExecuteCompare(rec, req)
+
+	if expected := `"program_id":"base","code":"debt_load_too_high","reason":"показатель долговой нагрузки превышает допустимый: `; !strings.Contains(rec.Body.String(), expected) {
+		t.Errorf("Expected the ineligible program with the reason in Russian, but got %q", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/compare", bytes.NewBufferString(`{"object_cost":5000000,"initial_payment":1000000}`))
+	rec = httptest.NewRecorder()
+	ExecuteCompare(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, but got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func fillLoanCache(t *testing.T) {
+	t.Helper()
+	SetLoanStore(storage.NewMemoryStore())
+
+	results := []models.CalculationResult{
+		{
+			Params:     models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240},
+			Program:    models.Program{Salary: true},
+			Aggregates: models.Aggregates{Rate: decimal.NewFromInt(8), MonthlyPayment: models.MoneyFromInt(33457), Overpayment: models.MoneyFromInt(4029824)},
+		},
+		{
+			Params:     models.LoanParams{ObjectCost: 3000000, InitialPayment: 600000, Months: 180},
+			Program:    models.Program{Military: true},
+			Aggregates: models.Aggregates{Rate: decimal.NewFromInt(9), MonthlyPayment: models.MoneyFromInt(24342), Overpayment: models.MoneyFromInt(1981560)},
+		},
+		{
+			Params:     models.LoanParams{ObjectCost: 3000000, InitialPayment: 600000, Months: 180},
+			Program:    models.Program{Base: true},
+			Aggregates: models.Aggregates{Rate: decimal.NewFromInt(10), MonthlyPayment: models.MoneyFromInt(25790), Overpayment: models.MoneyFromInt(2242200)},
+		},
+		{
+			Params:     models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 120},
+			Program:    models.Program{Salary: true},
+			Aggregates: models.Aggregates{Rate: decimal.NewFromInt(8), MonthlyPayment: models.MoneyFromInt(24342), Overpayment: models.MoneyFromInt(1823560)},
+		},
+	}
+	for _, result := range results {
+		if _, err := loanCache.Save(result); err != nil {
+			t.Fatalf("Failed to fill cache: %v", err)
+		}
+	}
+}
+
+func getCachedLoansPage(t *testing.T, query string) models.CachedLoansPage {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/cache?"+query, nil)
+	rec := httptest.NewRecorder()
+	GetCachedLoans(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var page models.CachedLoansPage
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+	return page
+}
+
+func loanIDs(loans []models.CachedLoan) []int {
+	ids := make([]int, 0, len(loans))
+	for _, loan := range loans {
+		ids = append(ids, loan.ID)
+	}
+	return ids
+}
+
+func TestGetCachedLoans_Query(t *testing.T) {
+	fillLoanCache(t)
+
+	tests := []struct {
+		query     string
+		expectIDs []int
+		total     int
+		hasNext   bool
+	}{
+		{query: "limit=2", expectIDs: []int{0, 1}, total: 4, hasNext: true},
+		{query: "limit=2&offset=2", expectIDs: []int{2, 3}, total: 4},
+		{query: "offset=10", expectIDs: []int{}, total: 4},
+		{query: "program=salary", expectIDs: []int{0, 3}, total: 2},
+		{query: "min_object_cost=4000000&max_months=200", expectIDs: []int{3}, total: 1},
+		{query: "min_months=180&max_object_cost=3000000", expectIDs: []int{1, 2}, total: 2},
+		{query: "rate=9", expectIDs: []int{1}, total: 1},
+		{query: "rate=0", expectIDs: []int{}, total: 0},
+		{query: "sort=monthly_payment&limit=10", expectIDs: []int{1, 3, 2, 0}, total: 4},
+		{query: "sort=monthly_payment&order=desc&limit=10", expectIDs: []int{0, 2, 1, 3}, total: 4},
+		{query: "sort=overpayment&limit=3", expectIDs: []int{3, 1, 2}, total: 4, hasNext: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			page := getCachedLoansPage(t, tc.query)
+
+			if got := loanIDs(page.Items); !equalInts(got, tc.expectIDs) {
+				t.Errorf("Expected loans %v, but got %v", tc.expectIDs, got)
+			}
+			if page.Total != tc.total {
+				t.Errorf("Expected total %d, but got %d", tc.total, page.Total)
+			}
+			if hasNext := page.NextCursor != ""; hasNext != tc.hasNext {
+				t.Errorf("Expected next cursor %t, but got %q", tc.hasNext, page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestGetCachedLoans_SortedArray(t *testing.T) {
+	fillLoanCache(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/cache?sort=monthly_payment&order=desc", nil)
+	req.Header.Set(apiVersionHeader, "2")
+	rec := httptest.NewRecorder()
+	GetCachedLoans(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	// Sorting alone keeps the plain array of loans.
+	var loans []models.CachedLoan
+	if err := json.Unmarshal(rec.Body.Bytes(), &loans); err != nil {
+		t.Fatalf("Expected the array of loans, but got %q: %v", rec.Body.String(), err)
+	}
+	if got := loanIDs(loans); !equalInts(got, []int{0, 2, 1, 3}) {
+		t.Errorf("Expected loans %v, but got %v", []int{0, 2, 1, 3}, got)
+	}
+}
+
+func TestGetCachedLoans_Cursor(t *testing.T) {
+	fillLoanCache(t)
+
+	tests := []struct {
+		query     string
+		expectIDs [][]int
+	}{
+		{query: "limit=1&sort=monthly_payment", expectIDs: [][]int{{1}, {3}, {2}, {0}}},
+		{query: "limit=3&sort=monthly_payment&order=desc", expectIDs: [][]int{{0, 2, 1}, {3}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			page := getCachedLoansPage(t, tc.query)
+			for i, expected := range tc.expectIDs {
+				if got := loanIDs(page.Items); !equalInts(got, expected) {
+					t.Fatalf("Page %d: expected loans %v, but got %v", i, expected, got)
+				}
+				if i < len(tc.expectIDs)-1 {
+					page = getCachedLoansPage(t, tc.query+"&cursor="+page.NextCursor)
+				} else if page.NextCursor != "" {
+					t.Errorf("Expected no next cursor on the last page, but got %q", page.NextCursor)
+				}
+			}
+		})
+	}
+}
+
+func TestGetCachedLoans_CursorAfterDelete(t *testing.T) {
+	fillLoanCache(t)
+
+	page := getCachedLoansPage(t, "limit=2")
+	if err := loanCache.Delete(0); err != nil {
+		t.Fatalf("Failed to delete loan: %v", err)
+	}
+
+	// The deleted loan was on the first page, the second page still starts after it.
+	page = getCachedLoansPage(t, "limit=2&cursor="+page.NextCursor)
+	if got := loanIDs(page.Items); !equalInts(got, []int{2, 3}) {
+		t.Errorf("Expected loans [2 3], but got %v", got)
+	}
+}
+
+func TestGetCachedLoans_InvalidQuery(t *testing.T) {
+	fillLoanCache(t)
+	cursor := getCachedLoansPage(t, "limit=1").NextCursor
+
+	for _, query := range []string{"limit=0", "limit=5000", "offset=-1", "rate=high", "sort=rate", "order=up", "cursor=abc", "offset=0&cursor=" + cursor, "sort=overpayment&cursor=" + cursor, "x=1", "limit=2&x=1"} {
+		req := httptest.NewRequest(http.MethodGet, "/cache?"+query, nil)
+		rec := httptest.NewRecorder()
+		GetCachedLoans(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, but got %d", query, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
+
+func equalInts(left, right []int) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for i := range left {
+		if left[i] != right[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGetCachedLoan(t *testing.T) {
+	fillLoanCache(t)
+
+	tests := []struct {
+		id           string
+		expectedCode int
+		expectedBody string
+	}{
+		{id: "1", expectedCode: http.StatusOK},
+		{id: "42", expectedCode: http.StatusNotFound, expectedBody: `{"error":"loan 42 not found"}` + "\n"},
+		{id: "abc", expectedCode: http.StatusBadRequest, expectedBody: `{"error":"invalid loan id"}` + "\n"},
+	}
+
+	for _, tc := range tests {
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/cache/"+tc.id, nil), map[string]string{"id": tc.id})
+		rec := httptest.NewRecorder()
+		GetCachedLoan(rec, req)
+
+		if rec.Code != tc.expectedCode {
+			t.Errorf("%s: expected status %d, but got %d", tc.id, tc.expectedCode, rec.Code)
+		}
+		if tc.expectedBody != "" && rec.Body.String() != tc.expectedBody {
+			t.Errorf("%s: expected body %q, but got %q", tc.id, tc.expectedBody, rec.Body.String())
+		}
+	}
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/cache/1", nil), map[string]string{"id": "1"})
+	rec := httptest.NewRecorder()
+	GetCachedLoan(rec, req)
+
+	var loan models.CachedLoan
+	if err := json.Unmarshal(rec.Body.Bytes(), &loan); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+	if loan.ID != 1 || !loan.Program.Military {
+		t.Errorf("Expected military loan 1, but got %+v", loan)
+	}
+}
+
+func TestDeleteCachedLoan(t *testing.T) {
+	fillLoanCache(t)
+
+	for _, expectedCode := range []int{http.StatusNoContent, http.StatusNotFound} {
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/cache/2", nil), map[string]string{"id": "2"})
+		rec := httptest.NewRecorder()
+		DeleteCachedLoan(rec, req)
+
+		if rec.Code != expectedCode {
+			t.Errorf("Expected status %d, but got %d", expectedCode, rec.Code)
+		}
+	}
+
+	if loans, _ := loanCache.List(); len(loans) != 3 {
+		t.Errorf("Expected 3 loans after deletion, but got %d", len(loans))
+	}
+}
+
+func TestClearCachedLoans(t *testing.T) {
+	fillLoanCache(t)
+
+	req := httptest.NewRequest(http.MethodDelete, "/cache", nil)
+	rec := httptest.NewRecorder()
+	ClearCachedLoans(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d, but got %d", http.StatusNoContent, rec.Code)
+	}
+	if loans, _ := loanCache.List(); len(loans) != 0 {
+		t.Errorf("Expected empty cache, but got %d loans", len(loans))
+	}
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", formatJSON},
+		{"application/json", formatJSON},
+		{"*/*", formatJSON},
+		{"text/csv", formatCSV},
+		{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", formatXLSX},
+		{"text/csv;q=0.5, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", formatXLSX},
+		{"application/json;q=0.9, text/csv", formatCSV},
+		{"text/html, text/csv;q=0.8, */*;q=0.1", formatCSV},
+		{"image/png", formatJSON},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/cache", nil)
+		req.Header.Set("Accept", test.accept)
+		if format := negotiateFormat(req); format != test.expected {
+			t.Errorf("Accept %q: expected format %s, but got %s", test.accept, test.expected, format)
+		}
+	}
+}
+
+func TestExecuteLoanCalculation_Export(t *testing.T) {
+	SetLoanStore(storage.NewMemoryStore())
+	body := `{"object_cost": 5000000, "initial_payment": 1000000, "months": 240, "program": {"salary": true}}`
+
+	req := httptest.NewRequest(http.MethodPost, "/execute", bytes.NewBufferString(body))
+	req.Header.Set("Accept", "text/csv")
+	rec := httptest.NewRecorder()
+	ExecuteLoanCalculation(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "text/csv; charset=utf-8" {
+		t.Errorf("Expected CSV content type, but got %q", contentType)
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); disposition != `attachment; filename="calculation-0.csv"` {
+		t.Errorf("Unexpected Content-Disposition %q", disposition)
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 13+2+240 {
+		t.Fatalf("Expected aggregates, header and 240 payments, but got %d lines", len(lines))
+	}
+	if lines[7] != "monthly_payment,33457.60" {
+		t.Errorf("Expected the monthly payment line, but got %q", lines[7])
+	}
+	if lines[14] != "number,date,payment,interest,principal,prepayment,balance" {
+		t.Errorf("Expected the schedule header, but got %q", lines[14])
+	}
+
+	if loans, _ := loanCache.List(); len(loans) != 1 {
+		t.Errorf("Expected the exported calculation to be saved in the cache, but got %d loan(s)", len(loans))
+	}
+}
+
+func TestGetCachedLoans_Export(t *testing.T) {
+	fillLoanCache(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/cache?program=salary", nil)
+	req.Header.Set("Accept", "text/csv")
+	rec := httptest.NewRecorder()
+	GetCachedLoans(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 loans, but got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[1], "0,5000000,1000000,240,salary,annuity,8,") {
+		t.Errorf("Unexpected loan line %q", lines[1])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/cache", nil)
+	req.Header.Set("Accept", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	rec = httptest.NewRecorder()
+	GetCachedLoans(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); disposition != `attachment; filename="loans.xlsx"` {
+		t.Errorf("Unexpected Content-Disposition %q", disposition)
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("PK")) {
+		t.Errorf("Expected a zip archive")
+	}
+}
+
+func TestExecuteOffer(t *testing.T) {
+	body := `{"object_cost": 5000000, "initial_payment": 1000000, "months": 240, "program": {"salary": true}}`
+	req := httptest.NewRequest(http.MethodPost, "/offer", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	ExecuteOffer(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/pdf" {
+		t.Errorf("Expected Content-Type 'application/pdf', but got %q", contentType)
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")) || !strings.Contains(rec.Body.String(), "(33 457.60 RUB) Tj") {
+		t.Errorf("Expected a PDF offer with the monthly payment")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/offer", bytes.NewBufferString(`{"object_cost": 100, "months": 12}`))
+	rec = httptest.NewRecorder()
+	ExecuteOffer(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, but got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	body = `{"object_cost": 5000000, "initial_payment": 1000000, "months": 240, "program": {"salary": true}, "borrower": {"income": 40000}}`
+	req = httptest.NewRequest(http.MethodPost, "/offer", bytes.NewBufferString(body))
+	rec = httptest.NewRecorder()
+	ExecuteOffer(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d, but got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"verdict":"rejected"`) {
+		t.Errorf("Expected the rejected affordability check, but got %q", rec.Body.String())
+	}
+}
+
+func TestGetMetrics(t *testing.T) {
+	SetLoanStore(storage.NewMemoryStore())
+	body := `{"object_cost": 5000000, "initial_payment": 1000000, "months": 240, "program": {"salary": true, "base": true}}`
+	ExecuteLoanCalculation(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/execute", bytes.NewBufferString(body)))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	GetMetrics(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("Expected the text exposition content type, but got %q", contentType)
+	}
+	for _, expected := range []string{
+		`mortgage_calculation_errors_total{error="multiple_programs"} `,
+		"# TYPE mortgage_cache_entries gauge\n",
+		`mortgage_cache_entries{cache="loans"} 0` + "\n",
+		`mortgage_cache_hit_ratio{cache="aggregates"} `,
+	} {
+		if !strings.Contains(rec.Body.String(), expected) {
+			t.Errorf("Expected the metrics to contain %q, but got:\n%s", expected, rec.Body.String())
+		}
+	}
+}
+
+// calculationErrorCount returns the number of the failed calculations with the error code counted so far.
+func calculationErrorCount(t *testing.T, code string) float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	GetMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	prefix := `mortgage_calculation_errors_total{error="` + code + `"} `
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, prefix); ok {
+			count, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("Failed to parse the counter %q: %v", line, err)
+			}
+			return count
+		}
+	}
+	return 0
+}
+
+func TestCalculationErrorsOfItems(t *testing.T) {
+	before := calculationErrorCount(t, "no_program_selected")
+
+	body := `[{"object_cost":5000000,"initial_payment":1000000,"months":240}]`
+	ExecuteBatch(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/execute/batch", bytes.NewBufferString(body)))
+
+	req := httptest.NewRequest(http.MethodPost, "/execute/stream", bytes.NewBufferString(`{"object_cost":5000000,"initial_payment":1000000,"months":240}`))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	ExecuteStream(httptest.NewRecorder(), req)
+
+	if count := calculationErrorCount(t, "no_program_selected") - before; count != 2 {
+		t.Errorf("Expected the batch item and the stream line to be counted, but got %v", count)
+	}
+
+	before = calculationErrorCount(t, "debt_load_too_high")
+	body = `{"object_cost":5000000,"initial_payment":1000000,"months":240,"borrower":{"income":45000}}`
+	ExecuteCompare(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/compare", bytes.NewBufferString(body)))
+
+	if count := calculationErrorCount(t, "debt_load_too_high") - before; count != 1 {
+		t.Errorf("Expected the ineligible program to be counted, but got %v", count)
+	}
+}
+
+// loadingStore is a store that has not finished loading.
+type loadingStore struct {
+	storage.Store
+}
+
+func (loadingStore) Ready() bool {
+	return false
+}
+
+func TestHealthProbes(t *testing.T) {
+	defer SetDraining(false)
+	defer SetLoanStore(storage.NewMemoryStore())
+
+	probe := func(handler http.HandlerFunc) (int, string) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		var body map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to decode JSON response: %v", err)
+		}
+		return rec.Code, body["status"]
+	}
+
+	SetLoanStore(storage.NewMemoryStore())
+	if code, status := probe(GetHealth); code != http.StatusOK || status != "ok" {
+		t.Errorf("Expected healthy service, but got %d %q", code, status)
+	}
+	if code, status := probe(GetReadiness); code != http.StatusOK || status != "ok" {
+		t.Errorf("Expected ready service, but got %d %q", code, status)
+	}
+
+	SetLoanStore(loadingStore{storage.NewMemoryStore()})
+	if code, status := probe(GetReadiness); code != http.StatusServiceUnavailable || status != "loading" {
+		t.Errorf("Expected loading service, but got %d %q", code, status)
+	}
+
+	store, loaded := storage.OpenAsync(storage.TypeMemory, "", cache.Limits{})
+	SetLoanStore(store)
+	if err := <-loaded; err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	if code, status := probe(GetReadiness); code != http.StatusOK || status != "ok" {
+		t.Errorf("Expected ready service after loading, but got %d %q", code, status)
+	}
+
+	SetDraining(true)
+	if code, status := probe(GetReadiness); code != http.StatusServiceUnavailable || status != "draining" {
+		t.Errorf("Expected draining service, but got %d %q", code, status)
+	}
+	if code, _ := probe(GetHealth); code != http.StatusOK {
+		t.Errorf("Expected the liveness probe to pass while draining, but got %d", code)
+	}
 }
@@ -0,0 +1,42 @@
+// Package paths implements reverse calculation path service.
+package paths
+
+import (
+	"log/slog"
+	"net/http"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/models"
+)
+
+// ExecuteReverse handler for solving the loan from the target monthly payment.
+func ExecuteReverse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodPost)
+		return
+	}
+
+	legacy, ok := legacyResponse(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.ReverseRequest
+	if !readJSONRequest(w, r, &request) {
+		return
+	}
+
+	result, err := calculator.CalculateReverse(request)
+	if err != nil {
+		writeCalculationError(w, r, "Reverse calculation failed", err, http.StatusBadRequest)
+		return
+	}
+
+	if legacy {
+		writeJSONResponse(w, models.ReverseResponseV1{Result: result.V1(calculator.Rounding())}, http.StatusOK)
+	} else {
+		writeJSONResponse(w, models.ReverseResponse{Result: result}, http.StatusOK)
+	}
+	slog.InfoContext(r.Context(), "Reverse calculation completed",
+		"loan_sum", result.Aggregates.LoanSum.StringFixed(2), "months", result.Params.Months)
+}
@@ -0,0 +1,59 @@
+// Package paths implements schedule path service.
+package paths
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/models"
+)
+
+// ExecuteSchedule handler for building the month-by-month payment schedule.
+func ExecuteSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodPost)
+		return
+	}
+
+	legacy, ok := legacyResponse(w, r)
+	if !ok {
+		return
+	}
+
+	request, ok := readLoanRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := calculator.CalculateLoan(request)
+	if errors.Is(err, calculator.ErrDebtLoadTooHigh) {
+		writeRejection(w, r, legacy, err, result.Affordability)
+		return
+	}
+	if err != nil {
+		writeCalculationError(w, r, "Mortgage calculation failed", err, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := calculator.CalculatePaymentSchedule(request)
+	if err != nil {
+		writeCalculationError(w, r, "Schedule calculation failed", err, http.StatusBadRequest)
+		return
+	}
+
+	response := models.ScheduleResponse{
+		Result: models.ScheduleResult{
+			CalculationResult: result,
+			Schedule:          schedule,
+		},
+	}
+
+	if legacy {
+		writeJSONResponse(w, models.ScheduleResponseV1{Result: response.Result.V1(calculator.Rounding())}, http.StatusOK)
+	} else {
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+	slog.InfoContext(r.Context(), "Schedule built", "payments", len(schedule))
+}
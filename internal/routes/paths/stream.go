@@ -0,0 +1,166 @@
+// Package paths implements streaming batch path service.
+package paths
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"sbermortgagecalculator/internal/calculator"
+	"sbermortgagecalculator/internal/i18n"
+	"sbermortgagecalculator/internal/models"
+	"sbermortgagecalculator/internal/validation"
+)
+
+const (
+	ndjsonContentType = "application/x-ndjson"
+	maxStreamLineSize = 1 << 20 // Maximum size of a single request line in bytes.
+)
+
+// Codes of the stream items failed before the calculation.
+const (
+	itemCodeInvalidJSON    = "invalid_json"
+	itemCodeReadBodyFailed = "read_body_failed"
+)
+
+// ExecuteStream handler for calculating loan requests read as NDJSON line by line.
+// The results are streamed back as NDJSON in the order of the requests, so the memory use does not depend on the input size.
+// The calculations are not saved in the cache.
+func ExecuteStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, r, "http.method_not_allowed", http.StatusMethodNotAllowed, http.MethodPost)
+		return
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != ndjsonContentType {
+		writeJSONError(w, r, "http.unsupported_media_type", http.StatusUnsupportedMediaType, ndjsonContentType)
+		return
+	}
+
+	legacy, ok := legacyResponse(w, r)
+	if !ok {
+		return
+	}
+
+	// The run lasts as long as the input, so the server timeouts do not apply, and the results
+	// are written while the requests are still being read.
+	controller := http.NewResponseController(w)
+	for _, err := range []error{
+		controller.SetReadDeadline(time.Time{}),
+		controller.SetWriteDeadline(time.Time{}),
+		controller.EnableFullDuplex(),
+	} {
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			slog.ErrorContext(r.Context(), "Failed to prepare the stream", "error", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	language := requestLanguage(w, r)
+	pending := make(chan chan models.BatchItem, calculator.BatchLimits().Workers)
+	go readStream(ctx, r.Body, language, pending)
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	count, failed := 0, 0
+	for result := range pending {
+		item := <-result
+		if ctx.Err() != nil {
+			continue
+		}
+
+		if item.Error != "" {
+			item.Error = itemErrorMessage(language, item.Code, item.Error)
+		}
+		var line any = item
+		if legacy {
+			line = item.V1(calculator.Rounding())
+		}
+		if err := encoder.Encode(line); err != nil {
+			slog.ErrorContext(ctx, "Failed to write the stream", "error", err)
+			cancel()
+			continue
+		}
+		if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			slog.ErrorContext(ctx, "Failed to flush the stream", "error", err)
+		}
+
+		count++
+		if item.Error != "" {
+			failed++
+		}
+	}
+
+	slog.InfoContext(r.Context(), "Stream calculated", "requests", count, "failed", failed)
+}
+
+// readStream decodes the requests line by line and queues their calculations in order until the input ends.
+// The capacity of pending bounds the number of calculations in progress. The requests are validated,
+// the lines with unknown fields or values of a wrong type are reported with the errors of their fields,
+// and the errors of the lines that are not calculated are in the language.
+func readStream(ctx context.Context, body io.Reader, language string, pending chan<- chan models.BatchItem) {
+	defer close(pending)
+
+	queue := func(item func() models.BatchItem) bool {
+		result := make(chan models.BatchItem, 1)
+		go func() {
+			result <- item()
+		}()
+
+		select {
+		case pending <- result:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	validate := loanValidator(language)
+	index := 0
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		itemIndex := index
+		index++
+
+		var request models.LoanRequest
+		if err := decodeJSON(line, &request); err != nil {
+			item := models.BatchItem{Index: itemIndex, Code: itemCodeInvalidJSON, Error: i18n.Message(language, "http.invalid_json")}
+			if fieldErrors := validation.DecodeErrors(err, language); fieldErrors != nil {
+				err := calculator.ErrInvalidRequest
+				item = models.BatchItem{Index: itemIndex, Code: calculator.ErrorCode(err), Error: err.Error(), Errors: fieldErrors}
+			}
+			if !queue(func() models.BatchItem { return item }) {
+				return
+			}
+			continue
+		}
+
+		if !queue(func() models.BatchItem { return calculator.CalculateBatchItem(itemIndex, request, validate) }) {
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		slog.ErrorContext(ctx, "Failed to read the stream", "error", err)
+		item := models.BatchItem{Index: index, Code: itemCodeReadBodyFailed, Error: i18n.Message(language, "http.read_body_failed")}
+		queue(func() models.BatchItem { return item })
+	}
+}
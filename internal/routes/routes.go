@@ -10,5 +10,18 @@ import (
 // SetupRoutes sets handlers for paths.
 func SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/execute", paths.ExecuteLoanCalculation).Methods("POST")
+	router.HandleFunc("/execute/batch", paths.ExecuteBatch).Methods("POST")
+	router.HandleFunc("/execute/stream", paths.ExecuteStream).Methods("POST")
+	router.HandleFunc("/schedule", paths.ExecuteSchedule).Methods("POST")
+	router.HandleFunc("/early-repayment", paths.ExecuteEarlyRepayment).Methods("POST")
+	router.HandleFunc("/reverse", paths.ExecuteReverse).Methods("POST")
+	router.HandleFunc("/compare", paths.ExecuteCompare).Methods("POST")
+	router.HandleFunc("/offer", paths.ExecuteOffer).Methods("POST")
 	router.HandleFunc("/cache", paths.GetCachedLoans).Methods("GET")
+	router.HandleFunc("/cache", paths.ClearCachedLoans).Methods("DELETE")
+	router.HandleFunc("/cache/{id}", paths.GetCachedLoan).Methods("GET")
+	router.HandleFunc("/cache/{id}", paths.DeleteCachedLoan).Methods("DELETE")
+	router.HandleFunc("/metrics", paths.GetMetrics).Methods("GET")
+	router.HandleFunc("/healthz", paths.GetHealth).Methods("GET")
+	router.HandleFunc("/readyz", paths.GetReadiness).Methods("GET")
 }
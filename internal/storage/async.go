@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/models"
+)
+
+// ErrUnavailable is returned by the store that is still loading.
+var ErrUnavailable = errors.New("storage is still loading")
+
+// AsyncStore opens the store in the background, so the service can start while a persistent store replays its file.
+// Until the store is loaded the operations fail with ErrUnavailable.
+type AsyncStore struct {
+	mu     sync.RWMutex
+	store  Store
+	loaded chan struct{} // Closed when the opening is finished, successfully or not.
+}
+
+// OpenAsync starts opening the store of the given type and returns at once.
+// The done channel receives the result of the opening and is closed afterwards.
+func OpenAsync(storeType, path string, limits cache.Limits) (*AsyncStore, <-chan error) {
+	async := &AsyncStore{loaded: make(chan struct{})}
+	done := make(chan error, 1)
+
+	go func() {
+		defer close(done)
+		defer close(async.loaded)
+
+		store, err := Open(storeType, path, limits)
+		if err != nil {
+			done <- err
+			return
+		}
+
+		async.mu.Lock()
+		async.store = store
+		async.mu.Unlock()
+		done <- nil
+	}()
+
+	return async, done
+}
+
+// Ready reports whether the store is loaded.
+func (a *AsyncStore) Ready() bool {
+	_, err := a.current()
+	return err == nil
+}
+
+// Save stores the calculation result under the next identifier.
+func (a *AsyncStore) Save(result models.CalculationResult) (models.CachedLoan, error) {
+	store, err := a.current()
+	if err != nil {
+		return models.CachedLoan{}, err
+	}
+	return store.Save(result)
+}
+
+// Get returns the loan with the given identifier.
+func (a *AsyncStore) Get(id int) (models.CachedLoan, error) {
+	store, err := a.current()
+	if err != nil {
+		return models.CachedLoan{}, err
+	}
+	return store.Get(id)
+}
+
+// List returns all loans ordered by identifier.
+func (a *AsyncStore) List() ([]models.CachedLoan, error) {
+	store, err := a.current()
+	if err != nil {
+		return nil, err
+	}
+	return store.List()
+}
+
+// Delete removes the loan with the given identifier.
+func (a *AsyncStore) Delete(id int) error {
+	store, err := a.current()
+	if err != nil {
+		return err
+	}
+	return store.Delete(id)
+}
+
+// Clear removes all loans, identifiers are not reused.
+func (a *AsyncStore) Clear() error {
+	store, err := a.current()
+	if err != nil {
+		return err
+	}
+	return store.Clear()
+}
+
+// Stats returns the counters of the loans kept in RAM, zero while loading.
+func (a *AsyncStore) Stats() cache.Stats {
+	store, err := a.current()
+	if err != nil {
+		return cache.Stats{}
+	}
+	return store.Stats()
+}
+
+// Close waits for the opening to finish and closes the loaded store.
+func (a *AsyncStore) Close() error {
+	<-a.loaded
+
+	store, err := a.current()
+	if err != nil {
+		return nil
+	}
+	return store.Close()
+}
+
+// current returns the loaded store or ErrUnavailable.
+func (a *AsyncStore) current() (Store, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.store == nil {
+		return nil, ErrUnavailable
+	}
+	return a.store, nil
+}
@@ -0,0 +1,364 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/models"
+)
+
+// Operations recorded in the storage file. The clear records are written by the earlier versions,
+// the store now compacts the file instead.
+const (
+	opSave   = "save"
+	opDelete = "delete"
+	opClear  = "clear"
+)
+
+// fileRecord is a single line of the append-only storage file.
+type fileRecord struct {
+	Op   string             `json:"op"`
+	ID   int                `json:"id"`
+	Loan *models.CachedLoan `json:"loan,omitempty"`
+}
+
+// recordSpan locates a record line in the storage file.
+type recordSpan struct {
+	offset int64
+	size   int64
+}
+
+// FileStore keeps loans in RAM and appends every change to a JSON lines file,
+// which is replayed on start so loans and identifiers survive restarts.
+// The limits bound only the loans kept in RAM: a loan evicted from RAM is read back from the file.
+// The file is compacted to the records of the stored loans on start and when the store is cleared.
+type FileStore struct {
+	mu      sync.Mutex
+	memory  *MemoryStore
+	file    *os.File
+	records map[int]recordSpan // Save records of the stored loans by identifier.
+	size    int64              // Size of the storage file, the offset of the next record.
+}
+
+// OpenFileStore opens or creates the storage file, replays its records and compacts it.
+func OpenFileStore(path string, limits cache.Limits) (*FileStore, error) {
+	if path == "" {
+		return nil, ErrEmptyPath
+	}
+
+	file, err := openStorageFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &FileStore{memory: NewBoundedMemoryStore(limits), file: file, records: make(map[int]recordSpan)}
+	if err = store.replay(); err != nil {
+		_ = store.file.Close()
+		return nil, err
+	}
+	if err = store.compact(); err != nil {
+		_ = store.file.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// openStorageFile opens or creates the storage file for reading and appending.
+func openStorageFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage file: %w", err)
+	}
+	return file, nil
+}
+
+// Save stores the calculation result under the next identifier.
+func (f *FileStore) Save(result models.CalculationResult) (models.CachedLoan, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	loan := models.CachedLoan{ID: f.memory.peekID(), CalculationResult: result}
+	span, err := f.append(fileRecord{Op: opSave, ID: loan.ID, Loan: &loan})
+	if err != nil {
+		return models.CachedLoan{}, err
+	}
+	f.records[loan.ID] = span
+	f.memory.put(loan)
+	return loan, nil
+}
+
+// Get returns the loan with the given identifier, reading it from the file if it is not kept in RAM.
+func (f *FileStore) Get(id int) (models.CachedLoan, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if loan, err := f.memory.Get(id); err == nil {
+		return loan, nil
+	}
+
+	span, ok := f.records[id]
+	if !ok {
+		return models.CachedLoan{}, ErrNotFound
+	}
+	loan, err := f.read(span)
+	if err != nil {
+		return models.CachedLoan{}, err
+	}
+	f.memory.put(loan)
+	return loan, nil
+}
+
+// List returns all loans ordered by identifier, reading the loans that are not kept in RAM from the file.
+func (f *FileStore) List() ([]models.CachedLoan, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cached, err := f.memory.List()
+	if err != nil {
+		return nil, err
+	}
+	inMemory := make(map[int]models.CachedLoan, len(cached))
+	for _, loan := range cached {
+		inMemory[loan.ID] = loan
+	}
+
+	loans := make([]models.CachedLoan, 0, len(f.records))
+	for _, id := range f.ids() {
+		loan, ok := inMemory[id]
+		if !ok {
+			if loan, err = f.read(f.records[id]); err != nil {
+				return nil, err
+			}
+		}
+		loans = append(loans, loan)
+	}
+	return loans, nil
+}
+
+// Delete removes the loan with the given identifier.
+func (f *FileStore) Delete(id int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.records[id]; !ok {
+		return ErrNotFound
+	}
+	if _, err := f.append(fileRecord{Op: opDelete, ID: id}); err != nil {
+		return err
+	}
+	delete(f.records, id)
+	f.memory.forget(id)
+	return nil
+}
+
+// Clear removes all loans and compacts the file, identifiers are not reused.
+func (f *FileStore) Clear() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.records = make(map[int]recordSpan)
+	if err := f.memory.Clear(); err != nil {
+		return err
+	}
+	return f.compact()
+}
+
+// Stats returns the counters of the loans kept in RAM.
+func (f *FileStore) Stats() cache.Stats {
+	return f.memory.Stats()
+}
+
+// Close closes the storage file.
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+// append writes the record as a new line of the storage file and returns its place in the file.
+func (f *FileStore) append(record fileRecord) (recordSpan, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return recordSpan{}, fmt.Errorf("failed to encode storage record: %w", err)
+	}
+	n, err := f.file.Write(append(data, '\n'))
+	span := recordSpan{offset: f.size, size: int64(n)}
+	f.size += int64(n)
+	if err != nil {
+		return recordSpan{}, fmt.Errorf("failed to write storage record: %w", err)
+	}
+	return span, nil
+}
+
+// read returns the loan of the save record at the span of the storage file.
+func (f *FileStore) read(span recordSpan) (models.CachedLoan, error) {
+	data := make([]byte, span.size)
+	if _, err := f.file.ReadAt(data, span.offset); err != nil {
+		return models.CachedLoan{}, fmt.Errorf("failed to read storage file: %w", err)
+	}
+
+	var record fileRecord
+	if err := json.Unmarshal(data, &record); err != nil || record.Loan == nil {
+		return models.CachedLoan{}, fmt.Errorf("%w: offset %d", ErrCorruptedRecord, span.offset)
+	}
+	return *record.Loan, nil
+}
+
+// ids returns the identifiers of the stored loans in ascending order.
+func (f *FileStore) ids() []int {
+	ids := make([]int, 0, len(f.records))
+	for id := range f.records {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// compact rewrites the storage file with only the save records of the stored loans, unless it has nothing else.
+// When the last identifier belongs to a removed loan, a delete record of it keeps the identifier sequence.
+// The new file replaces the old one by a rename, so a crash leaves one of them whole.
+func (f *FileStore) compact() error {
+	var sequence []byte
+	next := f.memory.peekID()
+	if _, ok := f.records[next-1]; next > 0 && !ok {
+		data, err := json.Marshal(fileRecord{Op: opDelete, ID: next - 1})
+		if err != nil {
+			return fmt.Errorf("failed to encode storage record: %w", err)
+		}
+		sequence = append(data, '\n')
+	}
+
+	size := int64(len(sequence))
+	for _, span := range f.records {
+		size += span.size
+	}
+	if size == f.size {
+		return nil
+	}
+
+	path := f.file.Name()
+	temp, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) // #nosec G304
+	if err != nil {
+		return fmt.Errorf("failed to compact storage file: %w", err)
+	}
+	records, err := f.copyRecords(temp, sequence)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(temp.Name())
+		return fmt.Errorf("failed to compact storage file: %w", err)
+	}
+
+	file, err := openStorageFile(path)
+	if err != nil {
+		return err
+	}
+	_ = f.file.Close()
+	f.file, f.records, f.size = file, records, size
+	slog.Info("Storage file compacted", "file", path, "loans", len(records), "size", size)
+	return nil
+}
+
+// copyRecords writes the save records of the stored loans in the order of identifiers followed by the sequence
+// record and returns their places in the written file.
+func (f *FileStore) copyRecords(output io.Writer, sequence []byte) (map[int]recordSpan, error) {
+	records := make(map[int]recordSpan, len(f.records))
+	var offset int64
+	for _, id := range f.ids() {
+		span := f.records[id]
+		data := make([]byte, span.size)
+		if _, err := f.file.ReadAt(data, span.offset); err != nil {
+			return nil, err
+		}
+		if _, err := output.Write(data); err != nil {
+			return nil, err
+		}
+		records[id] = recordSpan{offset: offset, size: span.size}
+		offset += span.size
+	}
+	if _, err := output.Write(sequence); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// replay restores the loans from the records of the storage file. A final line without the line break is a record
+// cut short by a crash in the middle of an append: it is cut off the file with a warning, so the next record starts
+// on a new line. A malformed complete line is a corrupted record.
+func (f *FileStore) replay() error {
+	reader := bufio.NewReader(f.file)
+	var offset int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) > 0 {
+				return f.truncate(offset, line)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read storage file: %w", err)
+		}
+
+		span := recordSpan{offset: offset, size: int64(len(data))}
+		offset += span.size
+		f.size = offset
+		if err = f.apply(data, span, line); err != nil {
+			return err
+		}
+	}
+}
+
+// apply replays a single record of the storage file at the span.
+func (f *FileStore) apply(data []byte, span recordSpan, line int) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+
+	var record fileRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("%w: line %d: %w", ErrCorruptedRecord, line, err)
+	}
+
+	switch {
+	case record.Op == opSave && record.Loan != nil:
+		f.records[record.Loan.ID] = span
+		f.memory.put(*record.Loan)
+	case record.Op == opDelete:
+		delete(f.records, record.ID)
+		f.memory.forget(record.ID)
+	case record.Op == opClear:
+		f.records = make(map[int]recordSpan)
+		return f.memory.Clear()
+	default:
+		return fmt.Errorf("%w: line %d: unknown operation %q", ErrCorruptedRecord, line, record.Op)
+	}
+	return nil
+}
+
+// truncate cuts the partial final line off the storage file at the offset.
+func (f *FileStore) truncate(offset int64, line int) error {
+	if err := f.file.Truncate(offset); err != nil {
+		return fmt.Errorf("failed to truncate storage file: %w", err)
+	}
+	slog.Warn("Partial storage record discarded", "file", f.file.Name(), "line", line)
+	return nil
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/models"
+)
+
+// MemoryStore keeps loans in RAM, they are lost on restart.
+type MemoryStore struct {
+	mu     sync.Mutex
+	loans  *cache.LRU[int, models.CachedLoan]
+	nextID int
+}
+
+// NewMemoryStore creates an empty unbounded in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return NewBoundedMemoryStore(cache.Limits{})
+}
+
+// NewBoundedMemoryStore creates an empty in-memory store that evicts the least recently used
+// and expired loans according to the limits.
+func NewBoundedMemoryStore(limits cache.Limits) *MemoryStore {
+	return &MemoryStore{loans: cache.New[int, models.CachedLoan](limits)}
+}
+
+// Save stores the calculation result under the next identifier.
+func (m *MemoryStore) Save(result models.CalculationResult) (models.CachedLoan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	loan := models.CachedLoan{ID: m.nextID, CalculationResult: result}
+	m.loans.Set(loan.ID, loan)
+	m.nextID++
+	return loan, nil
+}
+
+// Get returns the loan with the given identifier.
+func (m *MemoryStore) Get(id int) (models.CachedLoan, error) {
+	loan, ok := m.loans.Get(id)
+	if !ok {
+		return models.CachedLoan{}, ErrNotFound
+	}
+	return loan, nil
+}
+
+// List returns all loans ordered by identifier.
+func (m *MemoryStore) List() ([]models.CachedLoan, error) {
+	loans := m.loans.Values()
+	sort.Slice(loans, func(i, j int) bool {
+		return loans[i].ID < loans[j].ID
+	})
+	return loans, nil
+}
+
+// Delete removes the loan with the given identifier.
+func (m *MemoryStore) Delete(id int) error {
+	if !m.loans.Delete(id) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Clear removes all loans, identifiers are not reused.
+func (m *MemoryStore) Clear() error {
+	m.loans.Clear()
+	return nil
+}
+
+// Close does nothing for the in-memory store.
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// Stats returns the counters of the loans cache.
+func (m *MemoryStore) Stats() cache.Stats {
+	return m.loans.Stats()
+}
+
+// contains reports whether the loan is stored without touching the cache counters.
+func (m *MemoryStore) contains(id int) bool {
+	return m.loans.Contains(id)
+}
+
+// peekID returns the identifier the next saved loan will get.
+func (m *MemoryStore) peekID() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.nextID
+}
+
+// put stores the loan under its own identifier and moves the identifier sequence past it.
+func (m *MemoryStore) put(loan models.CachedLoan) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.loans.Set(loan.ID, loan)
+	m.nextID = max(m.nextID, loan.ID+1)
+}
+
+// forget removes the loan, keeping the identifier sequence so the identifier is never reused.
+func (m *MemoryStore) forget(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.loans.Delete(id)
+	m.nextID = max(m.nextID, id+1)
+}
@@ -0,0 +1,54 @@
+// Package storage implements persistence of the calculated loans.
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/models"
+)
+
+// Storage types selectable in the configuration.
+const (
+	TypeMemory = "memory"
+	TypeFile   = "file"
+)
+
+// Errors for storage operations.
+var (
+	ErrNotFound        = errors.New("loan not found")
+	ErrUnknownType     = errors.New("unknown storage type")
+	ErrEmptyPath       = errors.New("storage file path is empty")
+	ErrCorruptedRecord = errors.New("corrupted storage record")
+)
+
+// Store keeps the calculated loans and assigns them identifiers.
+type Store interface {
+	// Save stores the calculation result under the next identifier.
+	Save(result models.CalculationResult) (models.CachedLoan, error)
+	// Get returns the loan with the given identifier.
+	Get(id int) (models.CachedLoan, error)
+	// List returns all loans ordered by identifier.
+	List() ([]models.CachedLoan, error)
+	// Delete removes the loan with the given identifier.
+	Delete(id int) error
+	// Clear removes all loans, identifiers are not reused.
+	Clear() error
+	// Close releases the resources held by the store.
+	Close() error
+	// Stats returns the counters of the loans kept in RAM.
+	Stats() cache.Stats
+}
+
+// Open creates the store of the given type bounded by the limits, an empty type means the in-memory store.
+func Open(storeType, path string, limits cache.Limits) (Store, error) {
+	switch storeType {
+	case "", TypeMemory:
+		return NewBoundedMemoryStore(limits), nil
+	case TypeFile:
+		return OpenFileStore(path, limits)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, storeType)
+	}
+}
@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/models"
+)
+
+func newResult(months int) models.CalculationResult {
+	return models.CalculationResult{
+		Params:  models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: months},
+		Program: models.Program{Salary: true},
+		Aggregates: models.Aggregates{
+			Rate:           decimal.RequireFromString("7.4"),
+			MonthlyPayment: models.NewMoney(decimal.RequireFromString("31979.58")),
+		},
+	}
+}
+
+// assertSameLoans compares the loans by their JSON encoding, as decoded decimals differ in representation.
+func assertSameLoans(t *testing.T, expected, actual []models.CachedLoan) {
+	t.Helper()
+
+	expectedJSON, err := json.Marshal(expected)
+	assert.NoError(t, err)
+	actualJSON, err := json.Marshal(actual)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expectedJSON), string(actualJSON))
+}
+
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+
+	first, err := store.Save(newResult(120))
+	assert.NoError(t, err)
+	second, err := store.Save(newResult(240))
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID+1, second.ID)
+
+	loan, err := store.Get(second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, second, loan)
+
+	_, err = store.Get(second.ID + 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	loans, err := store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.CachedLoan{first, second}, loans)
+
+	assert.NoError(t, store.Delete(first.ID))
+	assert.ErrorIs(t, store.Delete(first.ID), ErrNotFound)
+
+	loans, err = store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.CachedLoan{second}, loans)
+
+	assert.NoError(t, store.Clear())
+	loans, err = store.List()
+	assert.NoError(t, err)
+	assert.Empty(t, loans)
+
+	third, err := store.Save(newResult(180))
+	assert.NoError(t, err)
+	assert.Equal(t, second.ID+1, third.ID)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMemoryStore_ListOrder(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 100; i++ {
+		_, err := store.Save(newResult(i + 1))
+		assert.NoError(t, err)
+	}
+
+	loans, err := store.List()
+	assert.NoError(t, err)
+	for i, loan := range loans {
+		assert.Equal(t, i, loan.ID)
+	}
+}
+
+func TestMemoryStore_Bounded(t *testing.T) {
+	store := NewBoundedMemoryStore(cache.Limits{MaxEntries: 2})
+	first, _ := store.Save(newResult(120))
+	second, _ := store.Save(newResult(180))
+
+	_, err := store.Get(first.ID)
+	assert.NoError(t, err)
+
+	third, _ := store.Save(newResult(240))
+	_, err = store.Get(second.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	loans, err := store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.CachedLoan{first, third}, loans)
+	assert.Equal(t, cache.Stats{Hits: 1, Misses: 1, Evictions: 1, Size: 2}, store.Stats())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := OpenFileStore(filepath.Join(t.TempDir(), "loans.jsonl"), cache.Limits{})
+	assert.NoError(t, err)
+	defer store.Close()
+
+	testStore(t, store)
+}
+
+func TestFileStore_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loans.jsonl")
+
+	store, err := OpenFileStore(path, cache.Limits{})
+	assert.NoError(t, err)
+	first, err := store.Save(newResult(120))
+	assert.NoError(t, err)
+	second, err := store.Save(newResult(240))
+	assert.NoError(t, err)
+	assert.NoError(t, store.Delete(second.ID))
+	assert.NoError(t, store.Close())
+
+	reopened, err := OpenFileStore(path, cache.Limits{})
+	assert.NoError(t, err)
+	defer reopened.Close()
+
+	loans, err := reopened.List()
+	assert.NoError(t, err)
+	assertSameLoans(t, []models.CachedLoan{first}, loans)
+
+	// Identifiers are never reused, even for deleted loans.
+	third, err := reopened.Save(newResult(180))
+	assert.NoError(t, err)
+	assert.Equal(t, second.ID+1, third.ID)
+}
+
+func TestFileStore_ClearSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loans.jsonl")
+
+	store, err := OpenFileStore(path, cache.Limits{})
+	assert.NoError(t, err)
+	first, err := store.Save(newResult(120))
+	assert.NoError(t, err)
+	assert.NoError(t, store.Clear())
+	assert.NoError(t, store.Close())
+
+	reopened, err := OpenFileStore(path, cache.Limits{})
+	assert.NoError(t, err)
+	defer reopened.Close()
+
+	loans, err := reopened.List()
+	assert.NoError(t, err)
+	assert.Empty(t, loans)
+
+	second, err := reopened.Save(newResult(240))
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID+1, second.ID)
+}
+
+func TestFileStore_Evicted(t *testing.T) {
+	store, err := OpenFileStore(filepath.Join(t.TempDir(), "loans.jsonl"), cache.Limits{MaxEntries: 1})
+	assert.NoError(t, err)
+	defer store.Close()
+
+	first, err := store.Save(newResult(120))
+	assert.NoError(t, err)
+	second, err := store.Save(newResult(240))
+	assert.NoError(t, err)
+
+	// The first loan is evicted from RAM but is still in the file.
+	loans, err := store.List()
+	assert.NoError(t, err)
+	assertSameLoans(t, []models.CachedLoan{first, second}, loans)
+
+	loan, err := store.Get(first.ID)
+	assert.NoError(t, err)
+	assertSameLoans(t, []models.CachedLoan{first}, []models.CachedLoan{loan})
+
+	assert.NoError(t, store.Delete(second.ID))
+	_, err = store.Get(second.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(first.ID))
+	assert.ErrorIs(t, store.Delete(first.ID), ErrNotFound)
+}
+
+func TestFileStore_Compaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loans.jsonl")
+
+	store, err := OpenFileStore(path, cache.Limits{})
+	assert.NoError(t, err)
+	first, err := store.Save(newResult(120))
+	assert.NoError(t, err)
+	second, err := store.Save(newResult(240))
+	assert.NoError(t, err)
+	assert.NoError(t, store.Delete(second.ID))
+	assert.NoError(t, store.Close())
+
+	// On start only the stored loan and the record keeping the identifier sequence remain.
+	reopened, err := OpenFileStore(path, cache.Limits{})
+	assert.NoError(t, err)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"op":"save","id":0`)
+	assert.Equal(t, `{"op":"delete","id":1}`, lines[1])
+
+	loans, err := reopened.List()
+	assert.NoError(t, err)
+	assertSameLoans(t, []models.CachedLoan{first}, loans)
+
+	// Clearing the store truncates the file to the identifier sequence.
+	assert.NoError(t, reopened.Clear())
+	data, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"op\":\"delete\",\"id\":1}\n", string(data))
+
+	third, err := reopened.Save(newResult(180))
+	assert.NoError(t, err)
+	assert.Equal(t, second.ID+1, third.ID)
+	assert.NoError(t, reopened.Close())
+
+	reopened, err = OpenFileStore(path, cache.Limits{})
+	assert.NoError(t, err)
+	defer reopened.Close()
+	loans, err = reopened.List()
+	assert.NoError(t, err)
+	assertSameLoans(t, []models.CachedLoan{third}, loans)
+}
+
+func TestFileStore_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loans.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte("{\"op\":\"save\",\"id\":0}\nnot json\n"), 0o600))
+
+	_, err := OpenFileStore(path, cache.Limits{})
+	assert.ErrorIs(t, err, ErrCorruptedRecord)
+}
+
+func TestFileStore_PartialLastRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loans.jsonl")
+
+	store, err := OpenFileStore(path, cache.Limits{})
+	assert.NoError(t, err)
+	first, err := store.Save(newResult(120))
+	assert.NoError(t, err)
+	assert.NoError(t, store.Close())
+
+	// A crash in the middle of the second append leaves half of the record without the line break.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	assert.NoError(t, err)
+	_, err = file.WriteString(`{"op":"save","id":1,"loan":{"id":1,"aggre`)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	reopened, err := OpenFileStore(path, cache.Limits{})
+	assert.NoError(t, err)
+	loans, err := reopened.List()
+	assert.NoError(t, err)
+	assertSameLoans(t, []models.CachedLoan{first}, loans)
+
+	// The next record starts on a new line and survives another restart.
+	second, err := reopened.Save(newResult(240))
+	assert.NoError(t, err)
+	assert.NoError(t, reopened.Close())
+
+	reopened, err = OpenFileStore(path, cache.Limits{})
+	assert.NoError(t, err)
+	defer reopened.Close()
+	loans, err = reopened.List()
+	assert.NoError(t, err)
+	assertSameLoans(t, []models.CachedLoan{first, second}, loans)
+}
+
+func TestFileStore_CorruptedMiddle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loans.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte("{\"op\":\"clear\"}\n{\"op\":\"sa\n{\"op\":\"clear\"}\n"), 0o600))
+
+	_, err := OpenFileStore(path, cache.Limits{})
+	assert.ErrorIs(t, err, ErrCorruptedRecord)
+}
+
+func TestOpen(t *testing.T) {
+	store, err := Open("", "", cache.Limits{})
+	assert.NoError(t, err)
+	assert.IsType(t, &MemoryStore{}, store)
+
+	store, err = Open(TypeFile, filepath.Join(t.TempDir(), "loans.jsonl"), cache.Limits{})
+	assert.NoError(t, err)
+	assert.IsType(t, &FileStore{}, store)
+	assert.NoError(t, store.Close())
+
+	_, err = Open(TypeFile, "", cache.Limits{})
+	assert.ErrorIs(t, err, ErrEmptyPath)
+
+	_, err = Open("redis", "", cache.Limits{})
+	assert.ErrorIs(t, err, ErrUnknownType)
+}
+
+func TestOpenAsync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loans.jsonl")
+	store, loaded := OpenAsync(TypeFile, path, cache.Limits{})
+	assert.NoError(t, <-loaded)
+	assert.True(t, store.Ready())
+	testStore(t, store)
+	assert.NoError(t, store.Close())
+
+	reopened, loaded := OpenAsync(TypeFile, path, cache.Limits{})
+	assert.NoError(t, <-loaded)
+	loans, err := reopened.List()
+	assert.NoError(t, err)
+	assert.Len(t, loans, 1)
+	assert.NoError(t, reopened.Close())
+}
+
+func TestOpenAsync_Unavailable(t *testing.T) {
+	store := &AsyncStore{loaded: make(chan struct{})}
+	assert.False(t, store.Ready())
+
+	_, err := store.Save(newResult(120))
+	assert.ErrorIs(t, err, ErrUnavailable)
+	_, err = store.List()
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorIs(t, store.Clear(), ErrUnavailable)
+	assert.Equal(t, cache.Stats{}, store.Stats())
+
+	failed, loaded := OpenAsync("redis", "", cache.Limits{})
+	assert.ErrorIs(t, <-loaded, ErrUnknownType)
+	assert.False(t, failed.Ready())
+	assert.NoError(t, failed.Close())
+}
@@ -6,8 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"sbermortgagecalculator/internal/cache"
+	"sbermortgagecalculator/internal/models"
 )
 
 // Errors for validation.
@@ -18,7 +22,52 @@ var (
 
 // Config yaml file.
 type Config struct {
-	Port int `yaml:"port"`
+	Port     int                  `yaml:"port"`
+	Programs []models.LoanProgram `yaml:"programs"`
+	Storage  StorageConfig        `yaml:"storage"`
+	Cache    CacheConfig          `yaml:"cache"`
+	Rounding models.RoundingMode  `yaml:"rounding"`
+	Logging  LoggingConfig        `yaml:"logging"`
+	Shutdown ShutdownConfig       `yaml:"shutdown"`
+	Calendar CalendarConfig       `yaml:"calendar"`
+	KeyRate  KeyRateConfig        `yaml:"key_rate"`
+
+	Affordability *models.AffordabilityLimits `yaml:"affordability"`
+	Batch         models.BatchLimits          `yaml:"batch"`
+}
+
+// CacheConfig bounds the in-memory caches.
+type CacheConfig struct {
+	Aggregates cache.Limits `yaml:"aggregates"` // Calculated aggregates by request.
+	Loans      cache.Limits `yaml:"loans"`      // Calculated loans kept in RAM by the store.
+}
+
+// LoggingConfig configures the structured logger.
+type LoggingConfig struct {
+	Format string `yaml:"format"` // Output format: text or json.
+	Level  string `yaml:"level"`  // Minimum level: debug, info, warn or error.
+}
+
+// ShutdownConfig controls the graceful shutdown on SIGINT or SIGTERM.
+type ShutdownConfig struct {
+	DrainDelay   time.Duration `yaml:"drain_delay"`   // Time to keep serving with the failing readiness probe.
+	DrainTimeout time.Duration `yaml:"drain_timeout"` // Time to wait for the requests in flight.
+}
+
+// CalendarConfig selects the production calendar of the payment dates.
+type CalendarConfig struct {
+	Path string `yaml:"path"` // Path of the calendar file relative to the configuration file, weekends only if empty.
+}
+
+// KeyRateConfig selects the key rate schedule of the floating rates.
+type KeyRateConfig struct {
+	Path string `yaml:"path"` // Path of the key rate file relative to the configuration file, no floating rates if empty.
+}
+
+// StorageConfig selects the store of the calculated loans.
+type StorageConfig struct {
+	Type string `yaml:"type"` // Store type: memory or file.
+	Path string `yaml:"path"` // Path of the storage file for the file store.
 }
 
 // LoadConfig read config from yml file.
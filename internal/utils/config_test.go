@@ -5,8 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"sbermortgagecalculator/internal/models"
 )
 
 func createTempConfigFile(t *testing.T, content string) string {
@@ -39,6 +42,104 @@ func TestLoadConfig_ValidFile(t *testing.T) {
 	assert.Equal(t, 8080, conf.Port)
 }
 
+func TestLoadConfig_Programs(t *testing.T) {
+	content := `port: 8080
+programs:
+  - id: family
+    name: Family mortgage
+    rate: 5.95
+    min_initial_percent: 15
+    min_months: 12
+    max_months: 360
+    min_loan_sum: 500000
+    max_loan_sum: 6000000
+  - id: floating
+    name: Floating rate
+    rate_periods:
+      - months: 24
+        rate: 5.9
+    key_rate_spread: 2.5
+`
+	fileName := createTempConfigFile(t, content)
+	defer os.Remove(fileName)
+
+	renamedFilePath := filepath.Join(filepath.Dir(fileName), "config.yml")
+	err := os.Rename(fileName, renamedFilePath)
+	assert.NoError(t, err)
+	defer os.Remove(renamedFilePath)
+
+	conf, err := LoadConfig(renamedFilePath)
+	assert.NoError(t, err)
+	assert.Len(t, conf.Programs, 2)
+	assert.Equal(t, "family", conf.Programs[0].ID)
+	assert.Equal(t, "5.95", conf.Programs[0].Rate.String())
+	assert.Equal(t, 360, conf.Programs[0].MaxMonths)
+	assert.Equal(t, 6000000, conf.Programs[0].MaxLoanSum)
+	assert.Nil(t, conf.Programs[0].KeyRateSpread)
+
+	floating := conf.Programs[1]
+	if assert.Len(t, floating.RatePeriods, 1) {
+		assert.Equal(t, 24, floating.RatePeriods[0].Months)
+		assert.Equal(t, "5.9", floating.RatePeriods[0].Rate.String())
+	}
+	if assert.NotNil(t, floating.KeyRateSpread) {
+		assert.Equal(t, "2.5", floating.KeyRateSpread.String())
+	}
+}
+
+func TestLoadConfig_Cache(t *testing.T) {
+	content := `port: 8080
+cache:
+  aggregates:
+    max_entries: 1000
+    ttl: 1h
+  loans:
+    max_entries: 50000
+rounding: ceil_ruble
+affordability:
+  approve_pdn: 40
+  max_pdn: 50.5
+  dependant_allowance: 12000
+batch:
+  max_size: 500
+  workers: 4
+logging:
+  format: json
+  level: debug
+shutdown:
+  drain_delay: 5s
+  drain_timeout: 1m
+calendar:
+  path: calendar.yml
+key_rate:
+  path: key_rate.yml
+`
+	fileName := createTempConfigFile(t, content)
+	defer os.Remove(fileName)
+
+	renamedFilePath := filepath.Join(filepath.Dir(fileName), "config.yml")
+	err := os.Rename(fileName, renamedFilePath)
+	assert.NoError(t, err)
+	defer os.Remove(renamedFilePath)
+
+	conf, err := LoadConfig(renamedFilePath)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, conf.Cache.Aggregates.MaxEntries)
+	assert.Equal(t, time.Hour, conf.Cache.Aggregates.TTL)
+	assert.Equal(t, 50000, conf.Cache.Loans.MaxEntries)
+	assert.Zero(t, conf.Cache.Loans.TTL)
+	assert.Equal(t, models.RoundingCeilRuble, conf.Rounding)
+	if assert.NotNil(t, conf.Affordability) {
+		assert.Equal(t, "50.5", conf.Affordability.MaxPDN.String())
+		assert.Equal(t, 12000, conf.Affordability.DependantAllowance)
+	}
+	assert.Equal(t, models.BatchLimits{MaxSize: 500, Workers: 4}, conf.Batch)
+	assert.Equal(t, LoggingConfig{Format: "json", Level: "debug"}, conf.Logging)
+	assert.Equal(t, ShutdownConfig{DrainDelay: 5 * time.Second, DrainTimeout: time.Minute}, conf.Shutdown)
+	assert.Equal(t, "calendar.yml", conf.Calendar.Path)
+	assert.Equal(t, "key_rate.yml", conf.KeyRate.Path)
+}
+
 func TestLoadConfig_InvalidFileName(t *testing.T) {
 	content := `port: 8080`
 	fileName := createTempConfigFile(t, content)
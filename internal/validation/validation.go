@@ -0,0 +1,199 @@
+// Package validation checks the fields of the requests before the calculation.
+//
+// The checks do not depend on the configured programs: the program selection and the program limits
+// are checked by the calculator and reported as calculation errors.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sbermortgagecalculator/internal/calendar"
+	"sbermortgagecalculator/internal/i18n"
+	"sbermortgagecalculator/internal/models"
+)
+
+// Error codes of the invalid fields.
+const (
+	CodeNotPositive  = "not_positive"
+	CodeNegative     = "negative"
+	CodeTooLarge     = "too_large"
+	CodeUnknownValue = "unknown_value"
+	CodeUnknownField = "unknown_field"
+	CodeInvalidType  = "invalid_type"
+	CodeInvalidDate  = "invalid_date"
+)
+
+// Upper limits of the request values, far above any real loan.
+const (
+	MaxMonths     = 600                    // Loan term of 50 years.
+	MaxAmount     = 1_000_000_000_000      // Money amounts in rubles.
+	MaxDependants = 100                    // Number of dependants of the borrower.
+	MaxPaymentDay = 31                     // Day of the month the payments are due.
+	unknownField  = "json: unknown field " // Prefix of the decoding error of an unknown field.
+)
+
+// Validate checks the decoded request and returns the errors of its fields with the messages in the language,
+// nil if the request is valid or of a type without field checks.
+func Validate(request any, language string) []models.FieldError {
+	switch value := request.(type) {
+	case *models.LoanRequest:
+		return LoanRequest(*value, language)
+	case *models.EarlyRepaymentRequest:
+		return EarlyRepaymentRequest(*value, language)
+	case *models.CompareRequest:
+		return CompareRequest(*value, language)
+	case *models.ReverseRequest:
+		return ReverseRequest(*value, language)
+	}
+	return nil
+}
+
+// LoanRequest checks the loan parameters, the payment scheme, the borrower, the payment day and the issue date.
+func LoanRequest(request models.LoanRequest, language string) []models.FieldError {
+	errs := fieldErrors{language: language}
+	errs.loanParams(request.LoanParams)
+	errs.paymentType(request.PaymentType)
+	errs.borrower(request.Borrower)
+	errs.notNegative("payment_day", request.PaymentDay, MaxPaymentDay)
+	errs.date("issue_date", request.IssueDate)
+	return errs.list
+}
+
+// EarlyRepaymentRequest checks the loan request and the prepayments.
+func EarlyRepaymentRequest(request models.EarlyRepaymentRequest, language string) []models.FieldError {
+	errs := fieldErrors{language: language, list: LoanRequest(request.LoanRequest, language)}
+	for i, prepayment := range request.Prepayments {
+		field := fmt.Sprintf("prepayments[%d].", i)
+
+		errs.positive(field+"month", prepayment.Month, MaxMonths)
+		if request.Months > 0 && prepayment.Month > request.Months {
+			errs.add(field+"month", CodeTooLarge, "after_term")
+		}
+		errs.positive(field+"amount", prepayment.Amount, MaxAmount)
+		if prepayment.Strategy != models.StrategyReduceTerm && prepayment.Strategy != models.StrategyReducePayment {
+			errs.add(field+"strategy", CodeUnknownValue, "unknown_strategy")
+		}
+		errs.notNegative(field+"every", prepayment.Every, MaxMonths)
+	}
+	return errs.list
+}
+
+// CompareRequest checks the loan parameters, the payment scheme and the borrower.
+func CompareRequest(request models.CompareRequest, language string) []models.FieldError {
+	errs := fieldErrors{language: language}
+	errs.loanParams(request.LoanParams)
+	errs.paymentType(request.PaymentType)
+	errs.borrower(request.Borrower)
+	return errs.list
+}
+
+// ReverseRequest checks the target payment, the optional term and loan sum, the payment scheme and the issue date.
+func ReverseRequest(request models.ReverseRequest, language string) []models.FieldError {
+	errs := fieldErrors{language: language}
+	errs.positive("monthly_payment", request.MonthlyPayment, MaxAmount)
+	errs.notNegative("months", request.Months, MaxMonths)
+	errs.notNegative("loan_sum", request.LoanSum, MaxAmount)
+	errs.paymentType(request.PaymentType)
+	errs.date("issue_date", request.IssueDate)
+	return errs.list
+}
+
+// DecodeErrors converts the error of decoding the request into the errors of its fields with the messages
+// in the language. It returns nil for malformed JSON, which has no field to report.
+func DecodeErrors(err error, language string) []models.FieldError {
+	errs := fieldErrors{language: language}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field != "" {
+			errs.add(typeErr.Field, CodeInvalidType, "invalid_type", typeErr.Type.String(), typeErr.Value)
+		}
+		return errs.list
+	}
+
+	if name, ok := strings.CutPrefix(err.Error(), unknownField); ok {
+		if unquoted, unquoteErr := strconv.Unquote(name); unquoteErr == nil {
+			name = unquoted
+		}
+		errs.add(name, CodeUnknownField, "unknown_field")
+	}
+
+	return errs.list
+}
+
+// fieldErrors collects the errors of the fields in the order of the checks.
+type fieldErrors struct {
+	language string
+	list     []models.FieldError
+}
+
+// add appends the error with the message of the field. key in the catalogue.
+func (e *fieldErrors) add(field, code, key string, args ...any) {
+	message := i18n.Message(e.language, i18n.PrefixField+key, args...)
+	e.list = append(e.list, models.FieldError{Field: field, Code: code, Message: message})
+}
+
+// positive checks that the value is greater than zero and does not exceed the limit.
+func (e *fieldErrors) positive(field string, value, limit int) {
+	if value <= 0 {
+		e.add(field, CodeNotPositive, "not_positive")
+		return
+	}
+	e.atMost(field, value, limit)
+}
+
+// notNegative checks that the value is zero or greater and does not exceed the limit.
+func (e *fieldErrors) notNegative(field string, value, limit int) {
+	if value < 0 {
+		e.add(field, CodeNegative, "negative")
+		return
+	}
+	e.atMost(field, value, limit)
+}
+
+func (e *fieldErrors) atMost(field string, value, limit int) {
+	if value > limit {
+		e.add(field, CodeTooLarge, "too_large", limit)
+	}
+}
+
+// date checks that the optional value is a date in the YYYY-MM-DD format.
+func (e *fieldErrors) date(field, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := time.Parse(calendar.DateLayout, value); err != nil {
+		e.add(field, CodeInvalidDate, "invalid_date")
+	}
+}
+
+func (e *fieldErrors) loanParams(params models.LoanParams) {
+	e.positive("object_cost", params.ObjectCost, MaxAmount)
+	e.notNegative("initial_payment", params.InitialPayment, MaxAmount)
+	if params.ObjectCost > 0 && params.InitialPayment >= params.ObjectCost {
+		e.add("initial_payment", CodeTooLarge, "not_below_cost")
+	}
+	e.positive("months", params.Months, MaxMonths)
+}
+
+func (e *fieldErrors) paymentType(paymentType string) {
+	switch paymentType {
+	case "", models.PaymentTypeAnnuity, models.PaymentTypeDifferentiated:
+	default:
+		e.add("payment_type", CodeUnknownValue, "unknown_payment_type")
+	}
+}
+
+func (e *fieldErrors) borrower(borrower *models.Borrower) {
+	if borrower == nil {
+		return
+	}
+	e.positive("borrower.income", borrower.Income, MaxAmount)
+	e.notNegative("borrower.obligations", borrower.Obligations, MaxAmount)
+	e.notNegative("borrower.dependants", borrower.Dependants, MaxDependants)
+}
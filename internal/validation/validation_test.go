@@ -0,0 +1,105 @@
+package validation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sbermortgagecalculator/internal/i18n"
+	"sbermortgagecalculator/internal/models"
+)
+
+func validRequest() models.LoanRequest {
+	return models.LoanRequest{
+		LoanParams: models.LoanParams{ObjectCost: 5000000, InitialPayment: 1000000, Months: 240},
+		Program:    models.Program{Salary: true},
+	}
+}
+
+func TestLoanRequest(t *testing.T) {
+	assert.Nil(t, LoanRequest(validRequest(), i18n.English))
+
+	request := validRequest()
+	request.InitialPayment = 6000000
+	request.Months = 100000
+	request.PaymentType = "bullet"
+	request.Borrower = &models.Borrower{Income: 0, Obligations: -1, Dependants: 101}
+	request.PaymentDay = 32
+	request.IssueDate = "2025-02-30"
+
+	assert.Equal(t, []models.FieldError{
+		{Field: "initial_payment", Code: CodeTooLarge, Message: "must be less than object_cost"},
+		{Field: "months", Code: CodeTooLarge, Message: "must not exceed 600"},
+		{Field: "payment_type", Code: CodeUnknownValue, Message: "must be annuity or differentiated"},
+		{Field: "borrower.income", Code: CodeNotPositive, Message: "must be greater than zero"},
+		{Field: "borrower.obligations", Code: CodeNegative, Message: "must not be negative"},
+		{Field: "borrower.dependants", Code: CodeTooLarge, Message: "must not exceed 100"},
+		{Field: "payment_day", Code: CodeTooLarge, Message: "must not exceed 31"},
+		{Field: "issue_date", Code: CodeInvalidDate, Message: "must be a date in the YYYY-MM-DD format"},
+	}, LoanRequest(request, i18n.English))
+
+	assert.Equal(t, []models.FieldError{
+		{Field: "object_cost", Code: CodeNotPositive, Message: "must be greater than zero"},
+		{Field: "initial_payment", Code: CodeNegative, Message: "must not be negative"},
+		{Field: "months", Code: CodeNotPositive, Message: "must be greater than zero"},
+	}, LoanRequest(models.LoanRequest{LoanParams: models.LoanParams{ObjectCost: -1, InitialPayment: -1}}, i18n.English))
+}
+
+func TestLoanRequest_Russian(t *testing.T) {
+	request := validRequest()
+	request.Months = 601
+
+	assert.Equal(t, []models.FieldError{
+		{Field: "months", Code: CodeTooLarge, Message: "не должно превышать 600"},
+	}, LoanRequest(request, i18n.Russian))
+}
+
+func TestEarlyRepaymentRequest(t *testing.T) {
+	request := models.EarlyRepaymentRequest{
+		LoanRequest: validRequest(),
+		Prepayments: []models.Prepayment{
+			{Month: 36, Amount: 500000, Strategy: models.StrategyReduceTerm, Every: 12},
+			{Month: 241, Amount: 0, Strategy: "skip", Every: -1},
+		},
+	}
+
+	assert.Equal(t, []models.FieldError{
+		{Field: "prepayments[1].month", Code: CodeTooLarge, Message: "must not exceed months"},
+		{Field: "prepayments[1].amount", Code: CodeNotPositive, Message: "must be greater than zero"},
+		{Field: "prepayments[1].strategy", Code: CodeUnknownValue, Message: "must be reduce_term or reduce_payment"},
+		{Field: "prepayments[1].every", Code: CodeNegative, Message: "must not be negative"},
+	}, EarlyRepaymentRequest(request, i18n.English))
+}
+
+func TestValidate(t *testing.T) {
+	request := validRequest()
+	assert.Nil(t, Validate(&request, i18n.English))
+	assert.Nil(t, Validate(&models.CompareRequest{LoanParams: request.LoanParams}, i18n.English))
+	assert.Nil(t, Validate(&[]models.LoanRequest{{}}, i18n.English), "batch requests are checked one by one")
+
+	assert.Equal(t, []models.FieldError{
+		{Field: "monthly_payment", Code: CodeNotPositive, Message: "must be greater than zero"},
+		{Field: "loan_sum", Code: CodeNegative, Message: "must not be negative"},
+		{Field: "issue_date", Code: CodeInvalidDate, Message: "must be a date in the YYYY-MM-DD format"},
+	}, Validate(&models.ReverseRequest{LoanSum: -1, IssueDate: "tomorrow"}, i18n.English))
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var request models.LoanRequest
+
+	decoder := json.NewDecoder(strings.NewReader(`{"borrower":{"income":"high"}}`))
+	assert.Equal(t, []models.FieldError{
+		{Field: "borrower.income", Code: CodeInvalidType, Message: "must be int, got string"},
+	}, DecodeErrors(decoder.Decode(&request), i18n.English))
+
+	decoder = json.NewDecoder(strings.NewReader(`{"rate":8}`))
+	decoder.DisallowUnknownFields()
+	assert.Equal(t, []models.FieldError{
+		{Field: "rate", Code: CodeUnknownField, Message: "is not a field of the request"},
+	}, DecodeErrors(decoder.Decode(&request), i18n.English))
+
+	assert.Nil(t, DecodeErrors(json.Unmarshal([]byte(`{"months":`), &request), i18n.English))
+	assert.Nil(t, DecodeErrors(json.Unmarshal([]byte(`[]`), &request), i18n.English))
+}